@@ -0,0 +1,85 @@
+package xio
+
+import "io"
+
+// StripANSIReader returns a reader that removes ANSI escape sequences, such as colors and cursor movements, from the
+// data read from r, for copying terminal output into a plain log. CSI sequences (ESC [), OSC and other string
+// sequences (ESC ], ESC P, ESC X, ESC ^ and ESC _, terminated by BEL or ESC \), and two byte escapes are removed, even
+// when split across reads. An incomplete sequence at the end of r is dropped.
+func StripANSIReader(r io.Reader) io.Reader {
+	return &ansiStripper{r: r}
+}
+
+type ansiState int
+
+const (
+	ansiText ansiState = iota
+	ansiEscape
+	ansiCSI
+	ansiString
+	ansiStringEscape
+)
+
+type ansiStripper struct {
+	r     io.Reader
+	state ansiState
+}
+
+func (as *ansiStripper) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for {
+		n, err := as.r.Read(p)
+		n = as.strip(p[:n])
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// strip removes escape sequences from p in place and returns the length of the remaining text.
+func (as *ansiStripper) strip(p []byte) int {
+	var n int
+	for _, b := range p {
+		switch as.state {
+		case ansiText:
+			if b == 0x1b {
+				as.state = ansiEscape
+				continue
+			}
+			p[n] = b
+			n++
+		case ansiEscape:
+			switch {
+			case b == '[':
+				as.state = ansiCSI
+			case b == ']', b == 'P', b == 'X', b == '^', b == '_':
+				as.state = ansiString
+			case b >= 0x20 && b <= 0x2f:
+				// intermediate bytes, the sequence ends with the next final byte.
+			default:
+				as.state = ansiText
+			}
+		case ansiCSI:
+			// parameter and intermediate bytes are in 0x20-0x3f, the final byte ends the sequence.
+			if b >= 0x40 && b <= 0x7e {
+				as.state = ansiText
+			}
+		case ansiString:
+			switch b {
+			case 0x07:
+				as.state = ansiText
+			case 0x1b:
+				as.state = ansiStringEscape
+			}
+		case ansiStringEscape:
+			if b == '\\' {
+				as.state = ansiText
+			} else if b != 0x1b {
+				as.state = ansiString
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,77 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStripANSIReader(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{name: "colors", src: "\x1b[1;31merror\x1b[0m: \x1b[38;5;208mdisk full\x1b[m\n", expected: "error: disk full\n"},
+		{name: "cursor movements", src: "50%\x1b[2K\x1b[1G100%\n", expected: "50%100%\n"},
+		{name: "window title", src: "\x1b]0;build\x07ok \x1b]8;;http://example.com\x1b\\link\x1b]8;;\x1b\\\n", expected: "ok link\n"},
+		{name: "two byte escapes", src: "\x1b7saved\x1b8 \x1b(Bcharset\n", expected: "saved charset\n"},
+		{name: "plain text", src: "no escapes here\n", expected: "no escapes here\n"},
+		{name: "incomplete sequence at the end", src: "done\x1b[3", expected: "done"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, src := range []struct {
+				kind string
+				r    io.Reader
+			}{
+				{kind: "whole", r: strings.NewReader(tc.src)},
+				// every escape sequence is split across reads
+				{kind: "one byte at a time", r: iotest.OneByteReader(strings.NewReader(tc.src))},
+			} {
+				var dst bytes.Buffer
+				if _, err := Copy(context.Background(), &dst, StripANSIReader(src.r)); err != nil {
+					t.Fatalf("%s: expected err to be nil but got %#q", src.kind, err)
+				}
+				if dst.String() != tc.expected {
+					t.Fatalf("%s: expected %q but got %q", src.kind, tc.expected, dst.String())
+				}
+			}
+		})
+	}
+}
+
+func TestStripANSIReaderBufferBoundaries(t *testing.T) {
+	const (
+		src      = "\x1b[1;31mred\x1b[0m \x1b]0;title\x1b\\\x1b7plain\x1b8\n"
+		expected = "red plain\n"
+	)
+
+	// every buffer size places the read boundaries at different offsets within the escape sequences
+	for size := 1; size <= len(src); size++ {
+		var dst bytes.Buffer
+		if _, err := Copy(context.Background(), &dst, StripANSIReader(strings.NewReader(src)), BufferSize(size)); err != nil {
+			t.Fatalf("buffer size %d: expected err to be nil but got %#q", size, err)
+		}
+		if dst.String() != expected {
+			t.Fatalf("buffer size %d: expected %q but got %q", size, expected, dst.String())
+		}
+	}
+}
+
+func TestStripANSIReaderEmptyRead(t *testing.T) {
+	r := StripANSIReader(strings.NewReader("\x1b[31mred\x1b[0m"))
+	if n, err := r.Read(nil); n != 0 || err != nil {
+		t.Fatalf("expected an empty read to return 0 and no error but got %d and %#q", n, err)
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+	if string(b) != "red" {
+		t.Fatalf("expected %q but got %q", "red", b)
+	}
+}
@@ -0,0 +1,56 @@
+package xio
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ErrSeekBackward is returned when seeking an append-only writer before its current offset.
+var ErrSeekBackward = errors.New("seek backward on append-only writer")
+
+// AppendOnlyW is a writer that only ever appends to the underlying io.WriterAt.
+type AppendOnlyW struct {
+	w      io.WriterAt
+	offset atomic.Int64
+}
+
+// AppendOnlyWriter returns a writer that writes sequentially to w via WriteAt starting at startOffset, which makes
+// it usable as the dst of Copy for append-only sinks such as audit logs. The offset only ever moves forward: writes
+// advance it by the number of bytes written and seeking before it fails with ErrSeekBackward.
+func AppendOnlyWriter(w io.WriterAt, startOffset int64) *AppendOnlyW {
+	aw := &AppendOnlyW{w: w}
+	aw.offset.Store(startOffset)
+	return aw
+}
+
+func (aw *AppendOnlyW) Write(p []byte) (int, error) {
+	n, err := aw.w.WriteAt(p, aw.offset.Load())
+	aw.offset.Add(int64(n))
+	return n, err
+}
+
+// Seek moves the offset of the next write. Seeking relative to the end is not supported since the end of w is
+// unknown, and seeking before the current offset fails with ErrSeekBackward.
+func (aw *AppendOnlyW) Seek(offset int64, whence int) (int64, error) {
+	current := aw.offset.Load()
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += current
+	default:
+		return current, errors.New("xio: unsupported seek whence on append-only writer")
+	}
+
+	if offset < current {
+		return current, ErrSeekBackward
+	}
+	aw.offset.Store(offset)
+	return offset, nil
+}
+
+// Offset returns the offset at which the next write happens. It is safe to call while a copy is in progress.
+func (aw *AppendOnlyW) Offset() int64 {
+	return aw.offset.Load()
+}
@@ -0,0 +1,97 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type writerAtFunc func([]byte, int64) (int, error)
+
+func (fn writerAtFunc) WriteAt(p []byte, off int64) (int, error) { return fn(p, off) }
+
+func TestAppendOnlyWriter(t *testing.T) {
+	t.Run("monotonic offsets", func(t *testing.T) {
+		var offsets []int64
+		data := make([]byte, 100)
+
+		w := AppendOnlyWriter(writerAtFunc(func(p []byte, off int64) (int, error) {
+			offsets = append(offsets, off)
+			return copy(data[off:], p), nil
+		}), 10)
+
+		n, err := Copy(context.Background(), w, strings.NewReader("hello world"), BufferSize(4))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 {
+			t.Fatalf("expected n to be 11 but got %d", n)
+		}
+
+		expected := []int64{10, 14, 18}
+		if len(offsets) != len(expected) {
+			t.Fatalf("expected writes at offsets %v but got %v", expected, offsets)
+		}
+		for i := range expected {
+			if offsets[i] != expected[i] {
+				t.Fatalf("expected writes at offsets %v but got %v", expected, offsets)
+			}
+		}
+
+		if offset := w.Offset(); offset != 21 {
+			t.Fatalf("expected offset to be 21 but got %d", offset)
+		}
+		if actual := string(data[10:21]); actual != "hello world" {
+			t.Fatalf("expected data to be written after the start offset but got %q", actual)
+		}
+	})
+
+	t.Run("short write", func(t *testing.T) {
+		writeErr := errors.New("disk full")
+
+		w := AppendOnlyWriter(writerAtFunc(func(p []byte, off int64) (int, error) {
+			return 2, writeErr
+		}), 0)
+
+		if _, err := w.Write([]byte("abcd")); err != writeErr {
+			t.Fatalf("expected err to be %#q but got %#q", writeErr, err)
+		}
+		if offset := w.Offset(); offset != 2 {
+			t.Fatalf("expected offset to only advance by the bytes written but got %d", offset)
+		}
+	})
+
+	t.Run("refuses to seek backward", func(t *testing.T) {
+		w := AppendOnlyWriter(writerAtFunc(func(p []byte, off int64) (int, error) { return len(p), nil }), 5)
+
+		if _, err := w.Write([]byte("abc")); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		for _, tc := range []struct {
+			offset int64
+			whence int
+		}{
+			{offset: 0, whence: io.SeekStart},
+			{offset: 7, whence: io.SeekStart},
+			{offset: -1, whence: io.SeekCurrent},
+		} {
+			if _, err := w.Seek(tc.offset, tc.whence); err != ErrSeekBackward {
+				t.Fatalf("expected err to be %#q but got %#q", ErrSeekBackward, err)
+			}
+		}
+		if offset := w.Offset(); offset != 8 {
+			t.Fatalf("expected offset to be unchanged but got %d", offset)
+		}
+
+		offset, err := w.Seek(2, io.SeekCurrent)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if offset != 10 || w.Offset() != 10 {
+			t.Fatalf("expected offset to be 10 but got %d", offset)
+		}
+	})
+}
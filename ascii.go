@@ -0,0 +1,49 @@
+package xio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNonASCII is returned by an ASCIIReader when it encounters a byte outside of the 7-bit ASCII range.
+var ErrNonASCII = errors.New("non ASCII byte")
+
+// OffsetError records the offset in the stream at which an error was detected.
+type OffsetError struct {
+	Err    error
+	Offset int64
+}
+
+func (e *OffsetError) Error() string { return fmt.Sprintf("%v at offset %d", e.Err, e.Offset) }
+
+func (e *OffsetError) Unwrap() error { return e.Err }
+
+// ASCIIReader returns a reader that passes through 7-bit clean data from r and fails on the first byte greater than
+// or equal to 0x80 with an *OffsetError wrapping ErrNonASCII. The bytes preceding it are returned along with the error.
+func ASCIIReader(r io.Reader) io.Reader {
+	return &asciiReader{r: r}
+}
+
+type asciiReader struct {
+	r      io.Reader
+	offset int64
+	err    error
+}
+
+func (ar *asciiReader) Read(p []byte) (int, error) {
+	if ar.err != nil {
+		return 0, ar.err
+	}
+
+	n, err := ar.r.Read(p)
+	for i, b := range p[:n] {
+		if b >= 0x80 {
+			ar.err = &OffsetError{Err: ErrNonASCII, Offset: ar.offset + int64(i)}
+			ar.offset += int64(i)
+			return i, ar.err
+		}
+	}
+	ar.offset += int64(n)
+	return n, err
+}
@@ -0,0 +1,42 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestASCIIReader(t *testing.T) {
+	t.Run("pure ascii", func(t *testing.T) {
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, ASCIIReader(strings.NewReader("plain old ascii\n")), BufferSize(4))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 16 || dst.String() != "plain old ascii\n" {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+
+	t.Run("embedded high byte", func(t *testing.T) {
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, ASCIIReader(strings.NewReader("plain café")), BufferSize(4))
+
+		if !errors.Is(err, ErrNonASCII) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrNonASCII, err)
+		}
+
+		var offsetErr *OffsetError
+		if !errors.As(err, &offsetErr) {
+			t.Fatalf("expected an offset error but got %T", err)
+		}
+		if offsetErr.Offset != 9 {
+			t.Fatalf("expected offset to be 9 but got %d", offsetErr.Offset)
+		}
+		if n != 9 || dst.String() != "plain caf" {
+			t.Fatalf("expected the ascii prefix to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+}
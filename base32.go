@@ -0,0 +1,27 @@
+package xio
+
+import (
+	"context"
+	"encoding/base32"
+	"io"
+)
+
+// CopyBase32 copies src into dst encoded with enc, such as base32.StdEncoding. The final, padded, block is written
+// once src is exhausted, so the encoding is only complete when CopyBase32 returns without error. The returned n counts
+// the bytes read from src.
+func CopyBase32(ctx context.Context, dst io.Writer, src io.Reader, enc *base32.Encoding, opts ...CopyOption) (int64, error) {
+	encoder := base32.NewEncoder(enc, dst)
+
+	n, err := Copy(ctx, encoder, src, opts...)
+	if err != nil {
+		return n, err
+	}
+	return n, encoder.Close()
+}
+
+// CopyBase32Decode copies the base32 data of src, encoded with enc, into dst decoded. Newlines in src are ignored,
+// and malformed or incorrectly padded input fails the copy with a base32.CorruptInputError. The returned n counts the
+// decoded bytes written to dst.
+func CopyBase32Decode(ctx context.Context, dst io.Writer, src io.Reader, enc *base32.Encoding, opts ...CopyOption) (int64, error) {
+	return Copy(ctx, dst, base32.NewDecoder(enc, src), opts...)
+}
@@ -0,0 +1,55 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCopyBase32(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		enc  *base32.Encoding
+	}{
+		{name: "padded", enc: base32.StdEncoding},
+		{name: "hex", enc: base32.HexEncoding},
+		{name: "unpadded", enc: base32.StdEncoding.WithPadding(base32.NoPadding)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, data := range []string{"", "f", "fo", "foo", "foob", "fooba", "foobar", strings.Repeat("radix ", 100)} {
+				var encoded bytes.Buffer
+				n, err := CopyBase32(context.Background(), &encoded, strings.NewReader(data), tc.enc, BufferSize(7))
+				if err != nil {
+					t.Fatalf("expected err to be nil but got %#q", err)
+				}
+				if n != int64(len(data)) {
+					t.Fatalf("expected n to be %d but got %d", len(data), n)
+				}
+				if expected := tc.enc.EncodeToString([]byte(data)); encoded.String() != expected {
+					t.Fatalf("expected %q to be encoded as %q but got %q", data, expected, encoded.String())
+				}
+
+				var decoded bytes.Buffer
+				n, err = CopyBase32Decode(context.Background(), &decoded, &encoded, tc.enc, BufferSize(7))
+				if err != nil {
+					t.Fatalf("expected err to be nil but got %#q", err)
+				}
+				if n != int64(len(data)) || decoded.String() != data {
+					t.Fatalf("expected %q to round trip but got %q", data, decoded.String())
+				}
+			}
+		})
+	}
+
+	t.Run("corrupt input", func(t *testing.T) {
+		_, err := CopyBase32Decode(context.Background(), &bytes.Buffer{}, strings.NewReader("MZ!W6==="), base32.StdEncoding)
+
+		var corrupt base32.CorruptInputError
+		if !errors.As(err, &corrupt) {
+			t.Fatalf("expected a corrupt input error but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// BitrateReader returns a reader that delivers the data of r at bitsPerSecond, as expected from a media stream.
+// It is the reader counterpart of RateLimit, expressed in bits: every Read waits until the bits read so far are due
+// since the first Read, so a single Read may return a burst of up to len(p) bytes. As with RateLimit, a non-positive
+// rate means no limit and r is returned as is. See BitrateReaderContext to cancel the waits.
+func BitrateReader(r io.Reader, bitsPerSecond int64) io.Reader {
+	return BitrateReaderContext(context.Background(), r, bitsPerSecond)
+}
+
+// BitrateReaderContext is like BitrateReader, but a wait is cut short when the context is canceled, in which case Read
+// returns the data read along with the context error.
+func BitrateReaderContext(ctx context.Context, r io.Reader, bitsPerSecond int64) io.Reader {
+	if bitsPerSecond <= 0 {
+		return r
+	}
+	rate := float64(bitsPerSecond) / 8
+	return &bitrateReader{ctx: ctx, r: r, rate: &rateRamp{start: rate, target: rate}}
+}
+
+type bitrateReader struct {
+	ctx   context.Context
+	r     io.Reader
+	rate  *rateRamp
+	start time.Time
+	read  int64
+}
+
+func (br *bitrateReader) Read(p []byte) (int, error) {
+	if br.start.IsZero() {
+		br.start = time.Now()
+	}
+
+	n, err := br.r.Read(p)
+	br.read += int64(n)
+	if waitErr := sleepUntil(br.ctx, br.start.Add(br.rate.elapsed(br.read))); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
@@ -0,0 +1,59 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestBitrateReader(t *testing.T) {
+	t.Run("delivers at the bitrate", func(t *testing.T) {
+		// 8000 bits per second is 1000 bytes per second, so 100 bytes take 100ms.
+		const (
+			bitsPerSecond = 8000
+			size          = 100
+			expected      = 100 * time.Millisecond
+		)
+
+		start := time.Now()
+		n, err := Copy(context.Background(), io.Discard, BitrateReader(bytes.NewReader(make([]byte, size)), bitsPerSecond), BufferSize(10))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != size {
+			t.Fatalf("expected n to be %d but got %d", size, n)
+		}
+
+		// Only the lower bound is tight, a loaded machine may take much longer.
+		if elapsed := time.Since(start); elapsed < expected || elapsed > 10*expected {
+			t.Fatalf("expected copy to take around %v but took %v", expected, elapsed)
+		}
+	})
+
+	t.Run("cancelable while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		n, err := Copy(context.Background(), io.Discard, BitrateReaderContext(ctx, bytes.NewReader(make([]byte, 1000)), 8), BufferSize(10))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+		if n != 10 {
+			t.Fatalf("expected the first chunk to be copied before the wait but got %d bytes", n)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Fatalf("expected the wait to be cut short on cancelation but took %v", elapsed)
+		}
+	})
+
+	t.Run("non-positive bitrate means no limit", func(t *testing.T) {
+		src := bytes.NewReader(nil)
+		if r := BitrateReader(src, 0); r != io.Reader(src) {
+			t.Fatal("expected the reader to be returned as is")
+		}
+	})
+}
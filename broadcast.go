@@ -0,0 +1,119 @@
+package xio
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrSlowConsumer is returned by the reader of a Broadcaster consumer that fell too far behind and was dropped.
+var ErrSlowConsumer = errors.New("slow consumer dropped")
+
+// Broadcaster is an io.WriteCloser fanning out the data written to it to any number of consumers, each reading at its
+// own pace. Writes never block on consumers: a consumer lagging more than its buffer allows is dropped instead, so that
+// a slow consumer doesn't hold the others back.
+type Broadcaster struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	capacity  int
+	consumers map[*consumer]struct{}
+	closed    bool
+}
+
+type consumer struct {
+	b   *Broadcaster
+	buf []byte
+	err error
+}
+
+// BroadcastWriter returns a Broadcaster buffering up to bufferPerConsumer unread bytes for every consumer. Use it as
+// the destination of a copy, and close it once the copy is done so that consumers reach io.EOF.
+func BroadcastWriter(bufferPerConsumer int) *Broadcaster {
+	b := &Broadcaster{capacity: bufferPerConsumer, consumers: map[*consumer]struct{}{}}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// AddConsumer returns a reader receiving the data written from now on, and a func removing the consumer. Once the
+// Broadcaster is closed the reader returns io.EOF after the buffered data. If the consumer falls more than
+// bufferPerConsumer bytes behind it is dropped: the reader returns what was buffered, and then ErrSlowConsumer.
+// After the consumer is removed its reader returns io.ErrClosedPipe.
+func (b *Broadcaster) AddConsumer() (io.Reader, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := &consumer{b: b}
+	if b.closed {
+		c.err = io.EOF
+	} else {
+		b.consumers[c] = struct{}{}
+	}
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.consumers, c)
+			c.buf, c.err = nil, io.ErrClosedPipe
+			b.cond.Broadcast()
+		})
+	}
+}
+
+// Write hands a copy of p to every consumer, dropping the ones it would push over their buffer. It fails with
+// io.ErrClosedPipe once the Broadcaster is closed.
+func (b *Broadcaster) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+	for c := range b.consumers {
+		if len(c.buf)+len(p) > b.capacity {
+			delete(b.consumers, c)
+			c.err = ErrSlowConsumer
+			continue
+		}
+		c.buf = append(c.buf, p...)
+	}
+	b.cond.Broadcast()
+	return len(p), nil
+}
+
+// Close ends the stream: consumers read io.EOF once they have drained their buffer.
+func (b *Broadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		for c := range b.consumers {
+			c.err = io.EOF
+		}
+		b.consumers = nil
+		b.cond.Broadcast()
+	}
+	return nil
+}
+
+func (c *consumer) Read(p []byte) (int, error) {
+	c.b.mu.Lock()
+	defer c.b.mu.Unlock()
+
+	for len(c.buf) == 0 && c.err == nil {
+		c.b.cond.Wait()
+	}
+	if len(c.buf) == 0 {
+		return 0, c.err
+	}
+
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	if len(c.buf) == 0 {
+		// release the backing array rather than let it grow from its tail forever
+		c.buf = nil
+	}
+	return n, nil
+}
@@ -0,0 +1,111 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBroadcaster(t *testing.T) {
+	t.Run("slow consumer is dropped", func(t *testing.T) {
+		const chunks = 10
+
+		b := BroadcastWriter(30)
+		fast, removeFast := b.AddConsumer()
+		defer removeFast()
+		slow, removeSlow := b.AddConsumer()
+		defer removeSlow()
+
+		// src only produces the next chunk once the fast consumer has read the previous one, while the slow consumer
+		// doesn't read at all until the copy is done.
+		ack := make(chan struct{}, 1)
+		ack <- struct{}{}
+		var i int
+		src := ReaderFunc(func(p []byte) (int, error) {
+			if i == chunks {
+				return 0, io.EOF
+			}
+			<-ack
+			i++
+			return copy(p, bytes.Repeat([]byte{'0' + byte(i%10)}, 10)), nil
+		})
+
+		fastData := make(chan []byte)
+		go func() {
+			var data []byte
+			chunk := make([]byte, 10)
+			for {
+				if _, err := io.ReadFull(fast, chunk); err != nil {
+					if err != io.EOF {
+						t.Errorf("expected fast consumer to reach EOF but got %#q", err)
+					}
+					fastData <- data
+					return
+				}
+				data = append(data, chunk...)
+				ack <- struct{}{}
+			}
+		}()
+
+		n, err := Copy(context.Background(), b, src, BufferSize(10))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 100 {
+			t.Fatalf("expected n to be 100 but got %d", n)
+		}
+		b.Close()
+
+		expected := "1111111111222222222233333333334444444444555555555566666666667777777777888888888899999999990000000000"
+		if data := <-fastData; string(data) != expected {
+			t.Fatalf("expected fast consumer to receive everything but got %q", data)
+		}
+
+		data, err := io.ReadAll(slow)
+		if !errors.Is(err, ErrSlowConsumer) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrSlowConsumer, err)
+		}
+		if string(data) != expected[:30] {
+			t.Fatalf("expected slow consumer to receive its buffer but got %q", data)
+		}
+	})
+
+	t.Run("removed consumer", func(t *testing.T) {
+		b := BroadcastWriter(10)
+		r, remove := b.AddConsumer()
+		remove()
+
+		if _, err := b.Write([]byte("hello")); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if _, err := r.Read(make([]byte, 5)); err != io.ErrClosedPipe {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrClosedPipe, err)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		b := BroadcastWriter(10)
+		r, remove := b.AddConsumer()
+		defer remove()
+
+		if _, err := io.Copy(b, strings.NewReader("hello")); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		b.Close()
+
+		if _, err := b.Write([]byte("late")); err != io.ErrClosedPipe {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrClosedPipe, err)
+		}
+		if data, err := io.ReadAll(r); err != nil || string(data) != "hello" {
+			t.Fatalf("expected %q and no error but got %q and %#q", "hello", data, err)
+		}
+
+		late, _ := b.AddConsumer()
+		if _, err := late.Read(make([]byte, 5)); err != io.EOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.EOF, err)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package xio
+
+// Content defined chunking uses a gear rolling hash: for every byte b the hash is updated as hash = hash<<1 + gear[b],
+// where gear is a fixed table of 256 pseudo random 64 bit values. Since older bytes are shifted out, the hash only
+// depends on the last 64 bytes, the window of the rolling hash. A chunk ends after a byte for which the top
+// ChunkMaskBits bits of the hash are all zero, giving an average chunk size of 2^ChunkMaskBits bytes, bounded
+// by MinChunkSize and MaxChunkSize.
+const (
+	MinChunkSize  = 2 * 1024
+	MaxChunkSize  = 64 * 1024
+	ChunkMaskBits = 13
+)
+
+const chunkMask = (1<<ChunkMaskBits - 1) << (64 - ChunkMaskBits)
+
+var gear [256]uint64
+
+func init() {
+	// splitmix64 with a fixed seed so that chunk boundaries are stable across processes and versions.
+	seed := uint64(0x786970636463) // "xiocdc"
+	for i := range gear {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gear[i] = z ^ (z >> 31)
+	}
+}
+
+// chunker accumulates the data written to it and calls fn with every content defined chunk.
+type chunker struct {
+	fn    func(chunk []byte) error
+	chunk []byte
+	hash  uint64
+}
+
+func (c *chunker) Write(p []byte) (int, error) {
+	for i, b := range p {
+		c.chunk = append(c.chunk, b)
+		c.hash = c.hash<<1 + gear[b]
+
+		if len(c.chunk) < MinChunkSize {
+			continue
+		}
+		if c.hash&chunkMask != 0 && len(c.chunk) < MaxChunkSize {
+			continue
+		}
+		if err := c.finish(); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+func (c *chunker) finish() error {
+	if len(c.chunk) == 0 {
+		return nil
+	}
+	err := c.fn(c.chunk)
+	c.chunk = c.chunk[:0]
+	c.hash = 0
+	return err
+}
@@ -0,0 +1,73 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestContentDefinedChunks(t *testing.T) {
+	data := make([]byte, 512*1024)
+	rand.New(rand.NewSource(42)).Read(data)
+
+	chunks := func(t *testing.T, data []byte, opts ...CopyOption) [][32]byte {
+		t.Helper()
+
+		var sums [][32]byte
+		var total int
+
+		opts = append(opts, ContentDefinedChunks(func(chunk []byte) error {
+			if len(chunk) > MaxChunkSize {
+				t.Errorf("chunk of %d bytes exceeds the maximum chunk size", len(chunk))
+			}
+			total += len(chunk)
+			sums = append(sums, sha256.Sum256(chunk))
+			return nil
+		}))
+
+		n, err := Copy(context.Background(), io.Discard, bytes.NewReader(data), opts...)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != int64(len(data)) || total != len(data) {
+			t.Fatalf("expected all %d bytes to be chunked but n was %d and chunks totaled %d", len(data), n, total)
+		}
+		return sums
+	}
+
+	reference := chunks(t, data)
+	if len(reference) < 16 {
+		t.Fatalf("expected many chunks for 512KiB of data but got %d", len(reference))
+	}
+
+	t.Run("stable regardless of read size", func(t *testing.T) {
+		for _, size := range []int{1000, 7777, 100 * 1024} {
+			if actual := chunks(t, data, BufferSize(size)); !reflect.DeepEqual(actual, reference) {
+				t.Fatalf("expected the same chunks with a buffer of %d bytes", size)
+			}
+		}
+	})
+
+	t.Run("resynchronizes after an insertion", func(t *testing.T) {
+		shifted := chunks(t, append([]byte("inserted prefix"), data...))
+
+		seen := make(map[[32]byte]bool)
+		for _, sum := range reference {
+			seen[sum] = true
+		}
+
+		var shared int
+		for _, sum := range shifted {
+			if seen[sum] {
+				shared++
+			}
+		}
+		if shared < len(reference)-2 {
+			t.Fatalf("expected all but the first chunks to be shared but only %d of %d were", shared, len(reference))
+		}
+	})
+}
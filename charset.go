@@ -0,0 +1,84 @@
+package xio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// CharsetReader returns a reader that transcodes the text read from r from the given charset to UTF-8 as it streams.
+// The supported charsets are latin1 (ISO-8859-1), windows-1252 and utf-8, the latter being passed through as is.
+// Names are case insensitive and common aliases are accepted. An error is returned for any other charset.
+func CharsetReader(r io.Reader, charset string) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8":
+		return r, nil
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1", "l1":
+		return &charsetReader{r: r, table: &latin1}, nil
+	case "windows-1252", "cp1252", "x-cp1252":
+		return &charsetReader{r: r, table: &windows1252}, nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+}
+
+var latin1, windows1252 [256]rune
+
+func init() {
+	for i := range latin1 {
+		latin1[i] = rune(i)
+	}
+
+	windows1252 = latin1
+	for i, r := range [32]rune{
+		'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+		'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+		'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+		'˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+	} {
+		windows1252[0x80+i] = r
+	}
+}
+
+// charsetReader decodes a single byte charset described by table into UTF-8. Since a byte may expand to several
+// bytes of UTF-8, encoded output that does not fit in the caller's buffer is kept for the next Read.
+type charsetReader struct {
+	r       io.Reader
+	table   *[256]rune
+	raw     []byte
+	out     []byte
+	pending []byte
+	// err is the error returned by r, kept until pending has been drained.
+	err error
+}
+
+func (cr *charsetReader) Read(p []byte) (int, error) {
+	if len(cr.pending) == 0 && len(p) > 0 {
+		if cr.err != nil {
+			return 0, cr.err
+		}
+		if cap(cr.raw) < len(p) {
+			cr.raw = make([]byte, len(p))
+		}
+		n, err := cr.r.Read(cr.raw[:len(p)])
+		cr.err = err
+
+		cr.out = cr.out[:0]
+		for _, b := range cr.raw[:n] {
+			cr.out = utf8.AppendRune(cr.out, cr.table[b])
+		}
+		cr.pending = cr.out
+
+		if len(cr.pending) == 0 {
+			return 0, err
+		}
+	}
+
+	n := copy(p, cr.pending)
+	cr.pending = cr.pending[n:]
+	if len(cr.pending) == 0 {
+		return n, cr.err
+	}
+	return n, nil
+}
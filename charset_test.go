@@ -0,0 +1,61 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCharsetReader(t *testing.T) {
+	for _, tc := range []struct {
+		charset  string
+		input    []byte
+		expected string
+	}{
+		{charset: "latin1", input: []byte("caf\xe9 na\xefve \xbfqu\xe9? \xa9"), expected: "café naïve ¿qué? ©"},
+		{charset: "ISO-8859-1", input: []byte("\x80\xff"), expected: "\u0080ÿ"},
+		{charset: "windows-1252", input: []byte("\x93quoted\x94 \x80100 \x85"), expected: "“quoted” €100 …"},
+		{charset: "utf-8", input: []byte("déjà vu"), expected: "déjà vu"},
+	} {
+		t.Run(tc.charset, func(t *testing.T) {
+			src, err := CharsetReader(bytes.NewReader(tc.input), tc.charset)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+
+			var dst bytes.Buffer
+			// a small buffer forces multibyte characters to be split across reads
+			if _, err := Copy(context.Background(), &dst, src, BufferSize(3)); err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if dst.String() != tc.expected {
+				t.Fatalf("expected %q but got %q", tc.expected, dst.String())
+			}
+		})
+	}
+
+	t.Run("unsupported charset", func(t *testing.T) {
+		if _, err := CharsetReader(bytes.NewReader(nil), "ebcdic"); err == nil {
+			t.Fatal("expected an error for an unsupported charset")
+		}
+	})
+	t.Run("error returned with data", func(t *testing.T) {
+		errBroken := errors.New("broken")
+		r, err := CharsetReader(ReaderFunc(func(b []byte) (int, error) {
+			return copy(b, "caf\xe9"), errBroken
+		}), "latin1")
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		var dst bytes.Buffer
+		_, err = Copy(context.Background(), &dst, r, BufferSize(4))
+		if err != errBroken {
+			t.Fatalf("expected err to be %#q but got %#q", errBroken, err)
+		}
+		if expected := "café"; dst.String() != expected {
+			t.Fatalf("expected %q but got %q", expected, dst.String())
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package xio
+
+import (
+	"fmt"
+	"io"
+)
+
+// CheckpointWriter returns a writer that calls save each time another every bytes have been written to w, with the
+// total offset written so far, so that an external system can persist a durable offset to resume from. Writes are
+// split at checkpoint boundaries so that save always receives a multiple of every. An error returned by save aborts
+// the write. every must be greater than zero, otherwise every Write fails with an error.
+func CheckpointWriter(w io.Writer, every int64, save func(offset int64) error) io.Writer {
+	cw := &checkpointWriter{w: w, every: every, save: save}
+	if every <= 0 {
+		cw.err = fmt.Errorf("xio: CheckpointWriter: invalid checkpoint interval %d", every)
+	}
+	return cw
+}
+
+type checkpointWriter struct {
+	w      io.Writer
+	every  int64
+	save   func(offset int64) error
+	offset int64
+	err    error
+}
+
+func (cw *checkpointWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+
+	var written int
+	for len(p) > 0 {
+		chunk := p
+		if remaining := cw.every - cw.offset%cw.every; int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+
+		n, err := cw.w.Write(chunk)
+		written += n
+		cw.offset += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		if cw.offset%cw.every == 0 {
+			if err := cw.save(cw.offset); err != nil {
+				return written, err
+			}
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
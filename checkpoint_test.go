@@ -0,0 +1,72 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointWriter(t *testing.T) {
+	t.Run("saves at expected offsets", func(t *testing.T) {
+		var offsets []int64
+		var dst bytes.Buffer
+
+		n, err := Copy(
+			context.Background(),
+			CheckpointWriter(&dst, 4, func(offset int64) error {
+				offsets = append(offsets, offset)
+				return nil
+			}),
+			strings.NewReader("0123456789abc"),
+			BufferSize(3),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 13 {
+			t.Fatalf("expected n to be 13 but got %d", n)
+		}
+		if dst.String() != "0123456789abc" {
+			t.Fatalf("expected dst to be %q but got %q", "0123456789abc", dst.String())
+		}
+		if expected := []int64{4, 8, 12}; !reflect.DeepEqual(offsets, expected) {
+			t.Fatalf("expected offsets to be %v but got %v", expected, offsets)
+		}
+	})
+
+	t.Run("save error aborts", func(t *testing.T) {
+		saveErr := errors.New("could not persist offset")
+
+		n, err := Copy(
+			context.Background(),
+			CheckpointWriter(io.Discard, 4, func(offset int64) error { return saveErr }),
+			strings.NewReader("0123456789abc"),
+		)
+		if err != saveErr {
+			t.Fatalf("expected err to be %#q but got %#q", saveErr, err)
+		}
+		if n != 4 {
+			t.Fatalf("expected n to be 4 but got %d", n)
+		}
+	})
+	t.Run("invalid interval", func(t *testing.T) {
+		for _, every := range []int64{0, -1} {
+			var dst bytes.Buffer
+			n, err := Copy(
+				context.Background(),
+				CheckpointWriter(&dst, every, func(offset int64) error {
+					t.Errorf("expected save not to be called but got offset %d", offset)
+					return nil
+				}),
+				strings.NewReader("0123456789abc"),
+			)
+			if err == nil || n != 0 || dst.Len() != 0 {
+				t.Fatalf("expected an interval of %d to fail without writing anything but got %d bytes and %#q", every, n, err)
+			}
+		}
+	})
+}
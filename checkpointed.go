@@ -0,0 +1,82 @@
+package xio
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrInvalidToken is returned by a copy given a resume token that was not produced by CopyCheckpointed.
+var ErrInvalidToken = errors.New("invalid resume token")
+
+// resumeTokenVersion prefixes the resume tokens, so that their format can evolve.
+const resumeTokenVersion = 1
+
+// CopyCheckpointed copies src into dst like Copy, and calls save with an opaque resume token at most every interval,
+// after a chunk has been written to dst. Once the copy ends, whatever the outcome, save is called once more if the copy
+// progressed since the last token. To restart an interrupted copy, pass the last token saved to the ResumeFrom option,
+// positioning dst past the bytes already written, for example by opening it in append mode. An error returned by save
+// aborts the copy. The token currently records the offset reached in src, see LoadOffset and SaveOffset which it
+// builds upon.
+func CopyCheckpointed(ctx context.Context, dst io.Writer, src io.Reader, interval time.Duration, save func(token []byte) error, opts ...CopyOption) (int64, error) {
+	var (
+		mu        sync.Mutex
+		lastSave  time.Time
+		offset    int64
+		saved     int64
+		haveSaved bool
+	)
+
+	checkpoint := func(c *copyoptions) {
+		saveOffset := c.saveOffset
+		c.saveOffset = func(off int64) error {
+			if saveOffset != nil {
+				if err := saveOffset(off); err != nil {
+					return err
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			offset = off
+			if haveSaved && time.Since(lastSave) < interval {
+				return nil
+			}
+			if err := save(resumeToken(off)); err != nil {
+				return err
+			}
+			lastSave, saved, haveSaved = time.Now(), off, true
+			return nil
+		}
+	}
+
+	n, err := Copy(ctx, dst, src, append(opts, checkpoint)...)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if offset > saved {
+		if saveErr := save(resumeToken(offset)); err == nil {
+			err = saveErr
+		}
+	}
+	return n, err
+}
+
+func resumeToken(offset int64) []byte {
+	return binary.AppendUvarint([]byte{resumeTokenVersion}, uint64(offset))
+}
+
+func parseResumeToken(token []byte) (int64, error) {
+	if len(token) < 2 || token[0] != resumeTokenVersion {
+		return 0, ErrInvalidToken
+	}
+	offset, n := binary.Uvarint(token[1:])
+	if n <= 0 || n != len(token)-1 || offset > 1<<63-1 {
+		return 0, ErrInvalidToken
+	}
+	return int64(offset), nil
+}
@@ -0,0 +1,97 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyCheckpointed(t *testing.T) {
+	const src = "the quick brown fox jumps over the lazy dog"
+
+	t.Run("resume from a saved token", func(t *testing.T) {
+		var token []byte
+		save := func(t []byte) error {
+			token = t
+			return nil
+		}
+
+		// the first run fails after 20 bytes
+		var dst bytes.Buffer
+		n, err := CopyCheckpointed(
+			context.Background(),
+			&dst,
+			&limitedReads{r: strings.NewReader(src), reads: 5},
+			0,
+			save,
+			BufferSize(4),
+		)
+		if err != errConnectionReset {
+			t.Fatalf("expected err to be %#q but got %#q", errConnectionReset, err)
+		}
+		if n != 20 || token == nil {
+			t.Fatalf("expected 20 bytes to be copied and a token to be saved but got %d bytes and %v", n, token)
+		}
+
+		n, err = CopyCheckpointed(context.Background(), &dst, strings.NewReader(src), time.Hour, save, ResumeFrom(token))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != int64(len(src)-20) {
+			t.Fatalf("expected the rest to be copied but got %d bytes", n)
+		}
+		if dst.String() != src {
+			t.Fatalf("expected %q but got %q", src, dst.String())
+		}
+
+		offset, err := parseResumeToken(token)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if offset != int64(len(src)) {
+			t.Fatalf("expected final token to hold offset %d but got %d", len(src), offset)
+		}
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		var saves int
+		_, err := CopyCheckpointed(context.Background(), &bytes.Buffer{}, strings.NewReader(src), time.Hour, func(token []byte) error {
+			saves++
+			return nil
+		}, BufferSize(4))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		// the first chunk and the end of the copy
+		if saves != 2 {
+			t.Fatalf("expected 2 saves but got %d", saves)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		for _, token := range [][]byte{nil, {0, 1}, {resumeTokenVersion}, {resumeTokenVersion, 0x80}} {
+			if _, err := Copy(context.Background(), &bytes.Buffer{}, strings.NewReader(src), ResumeFrom(token)); err != ErrInvalidToken {
+				t.Fatalf("expected err to be %#q for token %v but got %#q", ErrInvalidToken, token, err)
+			}
+		}
+	})
+}
+
+var errConnectionReset = errors.New("connection reset")
+
+// limitedReads fails once reads reads have been made from r.
+type limitedReads struct {
+	r     *strings.Reader
+	reads int
+}
+
+func (lr *limitedReads) Read(p []byte) (int, error) {
+	if lr.reads == 0 {
+		return 0, errConnectionReset
+	}
+	lr.reads--
+	return lr.r.Read(p)
+}
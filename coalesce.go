@@ -0,0 +1,108 @@
+package xio
+
+import (
+	"io"
+	"net"
+)
+
+// BuffersWriter is implemented by writers able to write several buffers at once, typically with a single
+// vectored write system call. *net.TCPConn is supported directly through net.Buffers.
+type BuffersWriter interface {
+	WriteBuffers(bufs net.Buffers) (int64, error)
+}
+
+// writeBuffers writes bufs to w using its BuffersWriter implementation if it has one, and net.Buffers otherwise.
+func writeBuffers(w io.Writer, bufs net.Buffers) (int64, error) {
+	if bw, ok := w.(BuffersWriter); ok {
+		return bw.WriteBuffers(bufs)
+	}
+	return bufs.WriteTo(w)
+}
+
+func bufferLen(bufs net.Buffers) int64 {
+	var n int64
+	for _, b := range bufs {
+		n += int64(len(b))
+	}
+	return n
+}
+
+// coalescingWriter holds up to max chunks before writing them out together.
+type coalescingWriter struct {
+	w       io.Writer
+	max     int
+	storage [][]byte
+	chunks  int
+}
+
+func (cw *coalescingWriter) Write(p []byte) (int, error) {
+	if cw.chunks == len(cw.storage) {
+		cw.storage = append(cw.storage, nil)
+	}
+	cw.storage[cw.chunks] = append(cw.storage[cw.chunks][:0], p...)
+	cw.chunks++
+
+	if cw.chunks < cw.max {
+		return len(p), nil
+	}
+	if err := cw.finish(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (cw *coalescingWriter) finish() error {
+	if cw.chunks == 0 {
+		return nil
+	}
+	bufs := make(net.Buffers, cw.chunks)
+	copy(bufs, cw.storage)
+	cw.chunks = 0
+
+	_, err := writeBuffers(cw.w, bufs)
+	return err
+}
+
+// CoalescingWriter returns a writer that accumulates writes and only forwards them to w, as a single write, once at
+// least minFlush bytes are buffered. This reduces round-trips to high-latency backends when copying in small chunks.
+// Close writes whatever remains buffered to w, but does not close w. Once a write to w fails, the error is returned
+// by every subsequent call.
+func CoalescingWriter(w io.Writer, minFlush int) io.WriteCloser {
+	return &minFlushWriter{w: w, min: minFlush}
+}
+
+type minFlushWriter struct {
+	w   io.Writer
+	min int
+	buf []byte
+	err error
+}
+
+func (mw *minFlushWriter) Write(p []byte) (int, error) {
+	if mw.err != nil {
+		return 0, mw.err
+	}
+	mw.buf = append(mw.buf, p...)
+	if len(mw.buf) >= mw.min {
+		return len(p), mw.flush()
+	}
+	return len(p), nil
+}
+
+func (mw *minFlushWriter) Close() error {
+	if mw.err != nil {
+		return mw.err
+	}
+	return mw.flush()
+}
+
+func (mw *minFlushWriter) flush() error {
+	if len(mw.buf) == 0 {
+		return nil
+	}
+	if _, mw.err = mw.w.Write(mw.buf); mw.err != nil {
+		return mw.err
+	}
+	mw.buf = mw.buf[:0]
+	return nil
+}
@@ -0,0 +1,176 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type vectoredWriter struct {
+	bytes.Buffer
+	writes        int
+	vectoredSizes []int
+}
+
+func (w *vectoredWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func (w *vectoredWriter) WriteBuffers(bufs net.Buffers) (int64, error) {
+	w.vectoredSizes = append(w.vectoredSizes, len(bufs))
+	var n int64
+	for _, b := range bufs {
+		m, err := w.Buffer.Write(b)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func TestCoalesceWrites(t *testing.T) {
+	const data = "0123456789abcdefghij"
+
+	t.Run("vectored writes", func(t *testing.T) {
+		dst := &vectoredWriter{}
+
+		n, err := Copy(context.Background(), dst, strings.NewReader(data), BufferSize(4), CoalesceWrites(3))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 20 {
+			t.Fatalf("expected n to be 20 but got %d", n)
+		}
+		if dst.String() != data {
+			t.Fatalf("expected dst to be %q but got %q", data, dst.String())
+		}
+		if dst.writes != 0 {
+			t.Fatalf("expected no sequential writes but got %d", dst.writes)
+		}
+		if expected := []int{3, 2}; !reflect.DeepEqual(dst.vectoredSizes, expected) {
+			t.Fatalf("expected vectored writes of %v chunks but got %v", expected, dst.vectoredSizes)
+		}
+	})
+
+	t.Run("falls back to sequential writes", func(t *testing.T) {
+		var writes []string
+
+		n, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				writes = append(writes, string(b))
+				return len(b), nil
+			}),
+			strings.NewReader(data),
+			BufferSize(8),
+			CoalesceWrites(2),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 20 {
+			t.Fatalf("expected n to be 20 but got %d", n)
+		}
+		if expected := []string{"01234567", "89abcdef", "ghij"}; !reflect.DeepEqual(writes, expected) {
+			t.Fatalf("expected writes to be %v but got %v", expected, writes)
+		}
+	})
+
+	t.Run("tcp connection", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Skipf("cannot listen on loopback: %v", err)
+		}
+		defer ln.Close()
+
+		received := make(chan string, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				received <- err.Error()
+				return
+			}
+			defer conn.Close()
+			data, _ := ReadAll(context.Background(), conn)
+			received <- string(data)
+		}()
+
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+
+		n, err := Copy(context.Background(), conn, strings.NewReader(data), BufferSize(4), CoalesceWrites(4))
+		conn.Close()
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 20 {
+			t.Fatalf("expected n to be 20 but got %d", n)
+		}
+		if actual := <-received; actual != data {
+			t.Fatalf("expected peer to receive %q but got %q", data, actual)
+		}
+	})
+}
+
+func TestCoalescingWriter(t *testing.T) {
+	t.Run("flush thresholds", func(t *testing.T) {
+		var writes []string
+		w := CoalescingWriter(WriterFunc(func(b []byte) (int, error) {
+			writes = append(writes, string(b))
+			return len(b), nil
+		}), 8)
+
+		n, err := Copy(context.Background(), w, strings.NewReader("abcdefghijklmnopqrstu"), BufferSize(3))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 21 {
+			t.Fatalf("expected n to be 21 but got %d", n)
+		}
+
+		expected := []string{"abcdefghi", "jklmnopqr"}
+		if !reflect.DeepEqual(writes, expected) {
+			t.Fatalf("expected writes to be %q but got %q", expected, writes)
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if expected = append(expected, "stu"); !reflect.DeepEqual(writes, expected) {
+			t.Fatalf("expected remainder to be flushed on close, writes to be %q but got %q", expected, writes)
+		}
+	})
+
+	t.Run("nothing buffered", func(t *testing.T) {
+		w := CoalescingWriter(WriterFunc(func(b []byte) (int, error) {
+			t.Fatalf("expected no writes but got %q", b)
+			return len(b), nil
+		}), 8)
+		if err := w.Close(); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+	})
+
+	t.Run("write error is sticky", func(t *testing.T) {
+		writeErr := errors.New("backend unavailable")
+		w := CoalescingWriter(WriterFunc(func(b []byte) (int, error) { return 0, writeErr }), 2)
+
+		if _, err := w.Write([]byte("abc")); err != writeErr {
+			t.Fatalf("expected err to be %#q but got %#q", writeErr, err)
+		}
+		if _, err := w.Write([]byte("d")); err != writeErr {
+			t.Fatalf("expected err to be %#q but got %#q", writeErr, err)
+		}
+		if err := w.Close(); err != writeErr {
+			t.Fatalf("expected err to be %#q but got %#q", writeErr, err)
+		}
+	})
+}
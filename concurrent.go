@@ -0,0 +1,58 @@
+package xio
+
+import (
+	"context"
+	"sync"
+)
+
+var concurrency = struct {
+	sync.Mutex
+	max    int
+	active int
+	// freed is closed and replaced whenever a slot frees up or the limit changes.
+	freed chan struct{}
+}{freed: make(chan struct{})}
+
+// SetMaxConcurrent bounds to n the number of copies started with the Concurrent option running at once in the
+// program, protecting memory and IO. Copies started beyond the limit wait for a slot, cancelably. Lowering the limit
+// doesn't interrupt copies already running, it only delays new ones until enough have completed. A limit of zero or
+// less, the default, lets any number of copies run.
+func SetMaxConcurrent(n int) {
+	concurrency.Lock()
+	defer concurrency.Unlock()
+
+	concurrency.max = n
+	close(concurrency.freed)
+	concurrency.freed = make(chan struct{})
+}
+
+// acquireConcurrent waits for a slot among the concurrent copies and returns the func releasing it.
+func acquireConcurrent(ctx context.Context) (release func(), err error) {
+	for {
+		concurrency.Lock()
+		if concurrency.max <= 0 || concurrency.active < concurrency.max {
+			concurrency.active++
+			concurrency.Unlock()
+
+			var once sync.Once
+			return func() { once.Do(releaseConcurrent) }, nil
+		}
+		freed := concurrency.freed
+		concurrency.Unlock()
+
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func releaseConcurrent() {
+	concurrency.Lock()
+	defer concurrency.Unlock()
+
+	concurrency.active--
+	close(concurrency.freed)
+	concurrency.freed = make(chan struct{})
+}
@@ -0,0 +1,101 @@
+package xio
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrent(t *testing.T) {
+	SetMaxConcurrent(2)
+	t.Cleanup(func() { SetMaxConcurrent(0) })
+
+	t.Run("copies queue and complete", func(t *testing.T) {
+		const copies = 6
+
+		var running, maxRunning atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < copies; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				n, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+					current := running.Add(1)
+					defer running.Add(-1)
+					for {
+						max := maxRunning.Load()
+						if current <= max || maxRunning.CompareAndSwap(max, current) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					return len(b), nil
+				}), strings.NewReader("hello"), Concurrent())
+				if err != nil {
+					t.Errorf("expected err to be nil but got %#q", err)
+				}
+				if n != 5 {
+					t.Errorf("expected n to be 5 but got %d", n)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if max := maxRunning.Load(); max != 2 {
+			t.Fatalf("expected at most 2 copies to run at once but got %d", max)
+		}
+	})
+
+	t.Run("canceled while queued", func(t *testing.T) {
+		unblock := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+					<-unblock
+					return len(b), nil
+				}), strings.NewReader("hello"), Concurrent())
+			}()
+		}
+		defer wg.Wait()
+		defer close(unblock)
+
+		// wait for both copies to hold their slot
+		for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+			concurrency.Lock()
+			active := concurrency.active
+			concurrency.Unlock()
+			if active == 2 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected 2 active copies but got %d", active)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		n, err := Copy(ctx, WriterFunc(func(b []byte) (int, error) {
+			t.Errorf("expected queued copy not to start")
+			return len(b), nil
+		}), strings.NewReader("hello"), Concurrent())
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+		if n != 0 {
+			t.Fatalf("expected n to be 0 but got %d", n)
+		}
+
+		// copies without the option are not bounded
+		if _, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) { return len(b), nil }), strings.NewReader("hello")); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package xio
+
+import (
+	"context"
+	"time"
+)
+
+// congestionPoll is how often Copy checks whether a congested dst has recovered.
+const congestionPoll = time.Millisecond
+
+// CongestionReporter is implemented by writers that can report congestion. When dst implements it, Copy doesn't read
+// from src while Congested returns true, letting dst push back without failing writes.
+type CongestionReporter interface {
+	Congested() bool
+}
+
+// waitUncongested polls cr until it is no longer congested or the context is canceled.
+func waitUncongested(ctx context.Context, cr CongestionReporter) error {
+	for cr.Congested() {
+		if err := sleep(ctx, congestionPoll); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type congestedWriter struct {
+	congested atomic.Bool
+	writes    []string
+}
+
+func (cw *congestedWriter) Write(p []byte) (int, error) {
+	cw.writes = append(cw.writes, string(p))
+	cw.congested.Store(true)
+	return len(p), nil
+}
+
+func (cw *congestedWriter) Congested() bool { return cw.congested.Load() }
+
+func TestCongestionReporter(t *testing.T) {
+	t.Run("pauses reads while congested", func(t *testing.T) {
+		dst := &congestedWriter{}
+
+		src := strings.NewReader("abcdef")
+
+		go func() {
+			for i := 0; i < 3; i++ {
+				time.Sleep(10 * time.Millisecond)
+				dst.congested.Store(false)
+			}
+		}()
+
+		start := time.Now()
+		n, err := Copy(
+			context.Background(),
+			dst,
+			ReaderFunc(func(b []byte) (int, error) {
+				if dst.Congested() {
+					t.Error("expected src not to be read while dst is congested")
+				}
+				return src.Read(b)
+			}),
+			BufferSize(2),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 6 || strings.Join(dst.writes, "") != "abcdef" {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.writes)
+		}
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Fatalf("expected copy to wait for congestion to clear but took %v", elapsed)
+		}
+	})
+
+	t.Run("cancelable while congested", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		dst := &congestedWriter{}
+		dst.congested.Store(true)
+
+		_, err := Copy(ctx, dst, ReaderFunc(func(b []byte) (int, error) {
+			t.Error("expected src not to be read while dst is congested")
+			return len(b), nil
+		}))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be context deadline exceeded but got %v", err)
+		}
+	})
+}
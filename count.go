@@ -0,0 +1,31 @@
+package xio
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+)
+
+// CountR is a reader that tallies the occurrences of a target byte as data passes through it.
+type CountR struct {
+	r      io.Reader
+	target byte
+	count  atomic.Int64
+}
+
+// CountByteReader returns a reader that counts the occurrences of target in the data read from r, for example
+// newlines, without requiring a second pass over the data. Count is safe to call while a copy is in progress.
+func CountByteReader(r io.Reader, target byte) *CountR {
+	return &CountR{r: r, target: target}
+}
+
+func (cr *CountR) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.count.Add(int64(bytes.Count(p[:n], []byte{cr.target})))
+	return n, err
+}
+
+// Count returns the number of occurrences of the target byte read so far.
+func (cr *CountR) Count() int64 {
+	return cr.count.Load()
+}
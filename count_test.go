@@ -0,0 +1,23 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCountByteReader(t *testing.T) {
+	src := CountByteReader(strings.NewReader("one\ntwo\nthree\n\nfive"), '\n')
+
+	n, err := Copy(context.Background(), io.Discard, src, BufferSize(3))
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+	if n != 19 {
+		t.Fatalf("expected n to be 19 but got %d", n)
+	}
+	if count := src.Count(); count != 4 {
+		t.Fatalf("expected count to be 4 but got %d", count)
+	}
+}
@@ -0,0 +1,77 @@
+package xio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnknownCompression is returned by DecompressReader when it can't tell which compression format to use.
+var ErrUnknownCompression = errors.New("unknown compression format")
+
+// DecompressReader returns a reader that decompresses r using the format selected by hint, which is either a format
+// name or a file name whose extension names it: gzip (.gz, .gzip), zlib (.zz, .zlib) or bzip2 (.bz2, .bzip2). When hint
+// does not name a format, the format is detected from the magic bytes at the start of r, and ErrUnknownCompression is
+// returned if it can't be. Errors reading the gzip or zlib header are returned right away.
+func DecompressReader(r io.Reader, hint string) (io.Reader, error) {
+	format := compressionFormat(hint)
+	if format == "" {
+		br := bufio.NewReader(r)
+		magic, err := br.Peek(3)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if format = sniffCompression(magic); format == "" {
+			return nil, ErrUnknownCompression
+		}
+		r = br
+	}
+
+	switch format {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "zlib":
+		return zlib.NewReader(r)
+	default:
+		return bzip2.NewReader(r), nil
+	}
+}
+
+// compressionFormat returns the format named by hint, or an empty string if it doesn't name any.
+func compressionFormat(hint string) string {
+	hint = strings.ToLower(hint)
+	if ext := filepath.Ext(hint); ext != "" {
+		hint = ext[1:]
+	}
+	switch hint {
+	case "gz", "gzip":
+		return "gzip"
+	case "zz", "zlib":
+		return "zlib"
+	case "bz2", "bzip2":
+		return "bzip2"
+	default:
+		return ""
+	}
+}
+
+// sniffCompression returns the format identified by the magic bytes at the start of data, or an empty string.
+func sniffCompression(magic []byte) string {
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return "gzip"
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return "bzip2"
+	case len(magic) >= 2 && magic[0]&0x0f == 8 && (uint16(magic[0])<<8|uint16(magic[1]))%31 == 0:
+		// zlib: deflate compression method and a valid header checksum.
+		return "zlib"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,56 @@
+package xio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDecompressReader(t *testing.T) {
+	data := strings.Repeat("mixed format ingestion ", 100)
+
+	var gzipped, zlibbed bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write([]byte(data))
+	gw.Close()
+	zw := zlib.NewWriter(&zlibbed)
+	zw.Write([]byte(data))
+	zw.Close()
+
+	for _, tc := range []struct {
+		name       string
+		compressed []byte
+		hint       string
+	}{
+		{name: "gzip extension", compressed: gzipped.Bytes(), hint: "logs/app.log.GZ"},
+		{name: "gzip name", compressed: gzipped.Bytes(), hint: "gzip"},
+		{name: "gzip magic", compressed: gzipped.Bytes()},
+		{name: "zlib extension", compressed: zlibbed.Bytes(), hint: "data.zz"},
+		{name: "zlib magic", compressed: zlibbed.Bytes(), hint: "data.bin"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := DecompressReader(bytes.NewReader(tc.compressed), tc.hint)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+
+			var dst bytes.Buffer
+			n, err := Copy(context.Background(), &dst, r)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != int64(len(data)) || dst.String() != data {
+				t.Fatalf("expected data to be decompressed but got %d bytes", n)
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := DecompressReader(strings.NewReader("plain text"), "notes.txt"); err != ErrUnknownCompression {
+			t.Fatalf("expected err to be %#q but got %#q", ErrUnknownCompression, err)
+		}
+	})
+}
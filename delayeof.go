@@ -0,0 +1,49 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// DelayEOFReader returns a reader that waits for delay once r returns io.EOF before returning it, for testing idle
+// timeouts and grace periods. Data returned along with io.EOF is returned right away, and the delayed io.EOF on the
+// next Read. See DelayEOFReaderContext to cancel the delay.
+func DelayEOFReader(r io.Reader, delay time.Duration) io.Reader {
+	return DelayEOFReaderContext(context.Background(), r, delay)
+}
+
+// DelayEOFReaderContext is like DelayEOFReader, but the delay is cut short when the context is canceled, in which case
+// Read returns the context error instead of io.EOF.
+func DelayEOFReaderContext(ctx context.Context, r io.Reader, delay time.Duration) io.Reader {
+	return &delayEOFReader{ctx: ctx, r: r, delay: delay}
+}
+
+type delayEOFReader struct {
+	ctx     context.Context
+	r       io.Reader
+	delay   time.Duration
+	eof     bool
+	delayed bool
+}
+
+func (dr *delayEOFReader) Read(p []byte) (int, error) {
+	if !dr.eof {
+		n, err := dr.r.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		dr.eof = true
+		if n > 0 {
+			return n, nil
+		}
+	}
+
+	if !dr.delayed {
+		if err := sleep(dr.ctx, dr.delay); err != nil {
+			return 0, err
+		}
+		dr.delayed = true
+	}
+	return 0, io.EOF
+}
@@ -0,0 +1,68 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDelayEOFReader(t *testing.T) {
+	t.Run("delays EOF", func(t *testing.T) {
+		const delay = 30 * time.Millisecond
+
+		start := time.Now()
+		var lastWrite time.Duration
+
+		n, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				lastWrite = time.Since(start)
+				return len(b), nil
+			}),
+			DelayEOFReader(strings.NewReader("hello"), delay),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 5 {
+			t.Fatalf("expected n to be 5 but got %d", n)
+		}
+		if lastWrite > delay/2 {
+			t.Fatalf("expected data to be copied right away but it was written at %v", lastWrite)
+		}
+		if elapsed := time.Since(start); elapsed < delay {
+			t.Fatalf("expected EOF to be delayed by %v but copy took %v", delay, elapsed)
+		}
+	})
+
+	t.Run("data returned with EOF is not delayed", func(t *testing.T) {
+		r := DelayEOFReader(ReaderFunc(func(b []byte) (int, error) { return copy(b, "last"), io.EOF }), time.Hour)
+
+		b := make([]byte, 8)
+		n, err := r.Read(b)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(b[:n]) != "last" {
+			t.Fatalf("expected %q but got %q", "last", b[:n])
+		}
+	})
+
+	t.Run("cancelable during the delay", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, DelayEOFReaderContext(ctx, strings.NewReader("hello"), time.Hour))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be context deadline exceeded but got %v", err)
+		}
+		if n != 5 {
+			t.Fatalf("expected the data to be copied before the delay but got %d bytes", n)
+		}
+	})
+}
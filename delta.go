@@ -0,0 +1,281 @@
+package xio
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrCorruptDelta is returned by ApplyDelta when the delta stream is malformed or refers to a block missing from base.
+var ErrCorruptDelta = errors.New("corrupt delta stream")
+
+// deltaMaxLiteral bounds the length of literal instructions, and the memory used to encode and apply them, up to an
+// extra block for the data remaining at the end of src.
+const deltaMaxLiteral = 64 * 1024
+
+// Delta stream instructions.
+const (
+	deltaCopy    byte = 'C'
+	deltaLiteral byte = 'L'
+)
+
+// CopyDelta writes to dst a delta stream describing src in terms of the blocks of base, a simple rsync-like delta.
+// base is split into blocks of blockSize bytes, and every block of src matching one of them, at any offset, is encoded
+// as an instruction to copy that block from base while the data in between is encoded as literal. Matches are found
+// with a rolling checksum and confirmed with SHA-256. The delta is applied with ApplyDelta given the same base and
+// blockSize. base is read entirely before the copy starts, but only the checksums of its blocks are kept in memory.
+// The returned n is the size of the delta stream written to dst.
+//
+// The delta stream is a sequence of instructions: 'C' followed by the uvarint index of the base block to copy, or 'L'
+// followed by the uvarint length of the literal data that follows it. An error is returned without reading anything if
+// blockSize is not positive.
+func CopyDelta(ctx context.Context, dst io.Writer, src, base io.Reader, blockSize int, opts ...CopyOption) (int64, error) {
+	if blockSize <= 0 {
+		return 0, fmt.Errorf("xio: CopyDelta: invalid block size %d", blockSize)
+	}
+	blocks := map[uint32][]deltaBlock{}
+
+	block := make([]byte, blockSize)
+	for index := uint64(0); ; index++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if _, err := io.ReadFull(base, block); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return 0, err
+		}
+		weak := newRollingSum(block).sum()
+		blocks[weak] = append(blocks[weak], deltaBlock{index: index, strong: sha256.Sum256(block)})
+	}
+
+	return Copy(ctx, dst, &deltaEncoder{src: src, size: blockSize, blocks: blocks}, opts...)
+}
+
+// ApplyDelta reconstructs into dst the data described by a delta stream written by CopyDelta, copying blocks from base
+// as instructed. base and blockSize must be the same as the ones given to CopyDelta. A malformed delta stream fails
+// with ErrCorruptDelta, and a non-positive blockSize with an error before anything is read.
+func ApplyDelta(ctx context.Context, dst io.Writer, base io.ReaderAt, delta io.Reader, blockSize int, opts ...CopyOption) (int64, error) {
+	if blockSize <= 0 {
+		return 0, fmt.Errorf("xio: ApplyDelta: invalid block size %d", blockSize)
+	}
+	return Copy(ctx, dst, &deltaApplier{delta: bufio.NewReader(delta), base: base, size: blockSize}, opts...)
+}
+
+type deltaBlock struct {
+	index  uint64
+	strong [sha256.Size]byte
+}
+
+// rollingSum is the weak checksum of rsync, which can be rolled over a stream one byte at a time.
+type rollingSum struct {
+	a, b uint32
+	size uint32
+}
+
+func newRollingSum(window []byte) rollingSum {
+	rs := rollingSum{size: uint32(len(window))}
+	for i, c := range window {
+		rs.a += uint32(c)
+		rs.b += uint32(len(window)-i) * uint32(c)
+	}
+	return rs
+}
+
+// roll slides the window by one byte, dropping out and adding in.
+func (rs *rollingSum) roll(out, in byte) {
+	rs.a += uint32(in) - uint32(out)
+	rs.b += rs.a - rs.size*uint32(out)
+}
+
+func (rs rollingSum) sum() uint32 {
+	return rs.a&0xffff | rs.b<<16
+}
+
+// deltaEncoder reads src and yields the delta stream describing it.
+type deltaEncoder struct {
+	src    io.Reader
+	size   int
+	blocks map[uint32][]deltaBlock
+
+	// buf holds the data of src not yet encoded, and i is the offset of the window within it. The bytes before the
+	// window are the literal data pending.
+	buf     []byte
+	i       int
+	sum     rollingSum
+	summed  bool
+	eof     bool
+	done    bool
+	out     []byte
+	pending []byte
+}
+
+func (de *deltaEncoder) Read(p []byte) (int, error) {
+	for len(de.pending) == 0 {
+		if de.done {
+			return 0, io.EOF
+		}
+		de.out = de.out[:0]
+		if err := de.step(); err != nil {
+			return 0, err
+		}
+		de.pending = de.out
+	}
+	n := copy(p, de.pending)
+	de.pending = de.pending[n:]
+	return n, nil
+}
+
+// step advances the window by a block when it matches one of base, and by a byte otherwise, appending the resulting
+// instructions to out.
+func (de *deltaEncoder) step() error {
+	// One byte past the window is needed to roll it.
+	for !de.eof && len(de.buf) <= de.i+de.size {
+		if len(de.buf) == cap(de.buf) {
+			de.buf = append(de.buf, make([]byte, de.size)...)[:len(de.buf)]
+		}
+		n, err := de.src.Read(de.buf[len(de.buf):cap(de.buf)])
+		de.buf = de.buf[:len(de.buf)+n]
+		if err == io.EOF {
+			de.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(de.buf)-de.i < de.size {
+		de.literal(len(de.buf))
+		de.done = true
+		return nil
+	}
+
+	if !de.summed {
+		de.sum, de.summed = newRollingSum(de.buf[de.i:de.i+de.size]), true
+	}
+
+	if index, ok := de.match(de.buf[de.i : de.i+de.size]); ok {
+		de.literal(de.i)
+		de.out = append(de.out, deltaCopy)
+		de.out = binary.AppendUvarint(de.out, index)
+		de.advance(de.size)
+		de.summed = false
+		return nil
+	}
+
+	if de.i == deltaMaxLiteral {
+		de.literal(de.i)
+		de.advance(0)
+	}
+	if len(de.buf) == de.i+de.size {
+		de.literal(len(de.buf))
+		de.done = true
+		return nil
+	}
+	de.sum.roll(de.buf[de.i], de.buf[de.i+de.size])
+	de.i++
+	return nil
+}
+
+func (de *deltaEncoder) match(window []byte) (uint64, bool) {
+	candidates := de.blocks[de.sum.sum()]
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	strong := sha256.Sum256(window)
+	for _, candidate := range candidates {
+		if candidate.strong == strong {
+			return candidate.index, true
+		}
+	}
+	return 0, false
+}
+
+// literal appends a literal instruction for the first n bytes of buf, if any.
+func (de *deltaEncoder) literal(n int) {
+	if n == 0 {
+		return
+	}
+	de.out = append(de.out, deltaLiteral)
+	de.out = binary.AppendUvarint(de.out, uint64(n))
+	de.out = append(de.out, de.buf[:n]...)
+}
+
+// advance drops the data encoded so far plus n bytes from buf, moving the window to its start.
+func (de *deltaEncoder) advance(n int) {
+	de.buf = de.buf[:copy(de.buf, de.buf[de.i+n:])]
+	de.i = 0
+}
+
+// deltaApplier reads a delta stream and yields the data it describes.
+type deltaApplier struct {
+	delta   *bufio.Reader
+	base    io.ReaderAt
+	size    int
+	buf     []byte
+	pending []byte
+}
+
+func (da *deltaApplier) Read(p []byte) (int, error) {
+	for len(da.pending) == 0 {
+		if err := da.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, da.pending)
+	da.pending = da.pending[n:]
+	return n, nil
+}
+
+// next decodes the next instruction into pending.
+func (da *deltaApplier) next() error {
+	op, err := da.delta.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	arg, err := binary.ReadUvarint(da.delta)
+	if err != nil {
+		return corruptDelta(err)
+	}
+
+	switch op {
+	case deltaCopy:
+		if cap(da.buf) < da.size {
+			da.buf = make([]byte, da.size)
+		}
+		da.buf = da.buf[:da.size]
+		if n, err := da.base.ReadAt(da.buf, int64(arg)*int64(da.size)); n < da.size {
+			return corruptDelta(err)
+		}
+	case deltaLiteral:
+		if arg > deltaMaxLiteral+uint64(da.size) {
+			return ErrCorruptDelta
+		}
+		if uint64(cap(da.buf)) < arg {
+			da.buf = make([]byte, arg)
+		}
+		da.buf = da.buf[:arg]
+		if _, err := io.ReadFull(da.delta, da.buf); err != nil {
+			return corruptDelta(err)
+		}
+	default:
+		return ErrCorruptDelta
+	}
+
+	da.pending = da.buf
+	return nil
+}
+
+// corruptDelta reports a delta stream ending within an instruction, or referring to data past the end of base, as
+// corrupt, and passes other errors through.
+func corruptDelta(err error) error {
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrCorruptDelta
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestCopyDelta(t *testing.T) {
+	random := func(seed int64, n int) []byte {
+		b := make([]byte, n)
+		rand.New(rand.NewSource(seed)).Read(b)
+		return b
+	}
+
+	base := random(1, 10*1024)
+
+	edited := append([]byte{}, base[:3000]...)
+	edited = append(edited, "inserted bytes"...)
+	edited = append(edited, base[3000:7000]...)
+	edited = append(edited, base[8000:]...)
+
+	for _, tc := range []struct {
+		name string
+		src  []byte
+	}{
+		{name: "identical", src: base},
+		{name: "edited", src: edited},
+		{name: "unrelated", src: random(2, 5000)},
+		{name: "larger than the literal limit", src: random(3, 3*deltaMaxLiteral)},
+		{name: "shorter than a block", src: []byte("tiny")},
+		{name: "empty", src: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var delta bytes.Buffer
+			n, err := CopyDelta(context.Background(), &delta, bytes.NewReader(tc.src), bytes.NewReader(base), 256, BufferSize(100))
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != int64(delta.Len()) {
+				t.Fatalf("expected n to be the delta size %d but got %d", delta.Len(), n)
+			}
+
+			var dst bytes.Buffer
+			n, err = ApplyDelta(context.Background(), &dst, bytes.NewReader(base), &delta, 256)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != int64(len(tc.src)) || !bytes.Equal(dst.Bytes(), tc.src) {
+				t.Fatalf("expected src to be reconstructed but got %d bytes", n)
+			}
+		})
+	}
+
+	t.Run("matching blocks are not sent", func(t *testing.T) {
+		var delta bytes.Buffer
+		if _, err := CopyDelta(context.Background(), &delta, bytes.NewReader(edited), bytes.NewReader(base), 256); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if delta.Len() > 1024 {
+			t.Fatalf("expected delta to be much smaller than src but got %d bytes", delta.Len())
+		}
+	})
+
+	t.Run("corrupt delta", func(t *testing.T) {
+		for _, delta := range []string{"C\x80", "C\x7f", "L\x05abc", "X\x00"} {
+			_, err := ApplyDelta(context.Background(), &bytes.Buffer{}, bytes.NewReader(base), bytes.NewReader([]byte(delta)), 256)
+			if !errors.Is(err, ErrCorruptDelta) {
+				t.Fatalf("expected err to be %#q for delta %q but got %#q", ErrCorruptDelta, delta, err)
+			}
+		}
+	})
+	t.Run("invalid block size", func(t *testing.T) {
+		for _, size := range []int{0, -1} {
+			var delta bytes.Buffer
+			if n, err := CopyDelta(context.Background(), &delta, bytes.NewReader(edited), bytes.NewReader(base), size); err == nil || n != 0 || delta.Len() != 0 {
+				t.Fatalf("expected CopyDelta to fail without writing anything for a block size of %d but got %d bytes and %#q", size, n, err)
+			}
+			var dst bytes.Buffer
+			if n, err := ApplyDelta(context.Background(), &dst, bytes.NewReader(base), bytes.NewReader([]byte("L\x01a")), size); err == nil || n != 0 || dst.Len() != 0 {
+				t.Fatalf("expected ApplyDelta to fail without writing anything for a block size of %d but got %d bytes and %#q", size, n, err)
+			}
+		}
+	})
+}
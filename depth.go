@@ -0,0 +1,52 @@
+package xio
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrMaxDepthExceeded is returned by a DepthLimitReader when the nesting of its input goes deeper than allowed.
+var ErrMaxDepthExceeded = errors.New("max nesting depth exceeded")
+
+// DepthLimitReader returns a reader that tracks the nesting of open and close bytes in the data read from r, such as
+// '[' and ']' in JSON, and fails with an *OffsetError wrapping ErrMaxDepthExceeded on the open byte taking the depth
+// beyond maxDepth, guarding against deeply nested untrusted input. The bytes preceding it are returned along with the
+// error. The reader doesn't validate the input otherwise: unmatched close bytes never take the depth below zero, and
+// open and close bytes are counted wherever they appear, including within strings.
+func DepthLimitReader(r io.Reader, open, close byte, maxDepth int) io.Reader {
+	return &depthLimitReader{r: r, open: open, close: close, max: maxDepth}
+}
+
+type depthLimitReader struct {
+	r           io.Reader
+	open, close byte
+	max         int
+	depth       int
+	offset      int64
+	err         error
+}
+
+func (dr *depthLimitReader) Read(p []byte) (int, error) {
+	if dr.err != nil {
+		return 0, dr.err
+	}
+
+	n, err := dr.r.Read(p)
+	for i, b := range p[:n] {
+		switch b {
+		case dr.open:
+			if dr.depth == dr.max {
+				dr.err = &OffsetError{Err: ErrMaxDepthExceeded, Offset: dr.offset + int64(i)}
+				dr.offset += int64(i)
+				return i, dr.err
+			}
+			dr.depth++
+		case dr.close:
+			if dr.depth > 0 {
+				dr.depth--
+			}
+		}
+	}
+	dr.offset += int64(n)
+	return n, err
+}
@@ -0,0 +1,52 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDepthLimitReader(t *testing.T) {
+	t.Run("under the limit", func(t *testing.T) {
+		src := `{"a":{"b":{"c":1}},"d":{"e":2}}`
+
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, DepthLimitReader(strings.NewReader(src), '{', '}', 3), BufferSize(4))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != int64(len(src)) || dst.String() != src {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+
+	t.Run("over nested", func(t *testing.T) {
+		src := "[[1],[[2,[[3]]]]]"
+
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, DepthLimitReader(strings.NewReader(src), '[', ']', 4), BufferSize(4))
+		if !errors.Is(err, ErrMaxDepthExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrMaxDepthExceeded, err)
+		}
+
+		var offsetErr *OffsetError
+		if !errors.As(err, &offsetErr) {
+			t.Fatalf("expected an offset error but got %T", err)
+		}
+		if offsetErr.Offset != 10 {
+			t.Fatalf("expected offset to be 10 but got %d", offsetErr.Offset)
+		}
+		if n != 10 || dst.String() != "[[1],[[2,[" {
+			t.Fatalf("expected the data before the fifth level to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+
+	t.Run("unmatched close", func(t *testing.T) {
+		var dst bytes.Buffer
+		if _, err := Copy(context.Background(), &dst, DepthLimitReader(strings.NewReader("]]]([)"), '(', ')', 1)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// wouldBlockPoll is how long HandleEAGAIN waits before retrying a write when no readiness func is given.
+const wouldBlockPoll = time.Millisecond
+
+type wouldBlockHandling struct {
+	enabled      bool
+	sentinel     error
+	waitWritable func(ctx context.Context) error
+}
+
+// wouldBlockWriter retries writes to w that fail with a would block error once the destination is writable again.
+type wouldBlockWriter struct {
+	ctx        context.Context
+	w          io.Writer
+	wouldBlock wouldBlockHandling
+}
+
+func (ww *wouldBlockWriter) Write(p []byte) (int, error) {
+	sentinel := ww.wouldBlock.sentinel
+	if sentinel == nil {
+		sentinel = errWouldBlock
+	}
+
+	var written int
+	for written < len(p) {
+		n, err := ww.w.Write(p[written:])
+		written += n
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, sentinel) {
+			return written, err
+		}
+
+		if ww.wouldBlock.waitWritable != nil {
+			err = ww.wouldBlock.waitWritable(ww.ctx)
+		} else {
+			err = sleep(ww.ctx, wouldBlockPoll)
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,8 @@
+//go:build !plan9
+
+package xio
+
+import "syscall"
+
+// errWouldBlock is the error recognized by HandleEAGAIN when no sentinel is given to WouldBlockError.
+var errWouldBlock error = syscall.EAGAIN
@@ -0,0 +1,6 @@
+//go:build plan9
+
+package xio
+
+// errWouldBlock is nil as there is no EAGAIN on this platform, so no error is recognized by default.
+var errWouldBlock error
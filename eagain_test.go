@@ -0,0 +1,85 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// eagainWriter writes half of what it is given and fails with err the first time it is called.
+type eagainWriter struct {
+	bytes.Buffer
+	err    error
+	failed bool
+}
+
+func (w *eagainWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		w.failed = true
+		n, _ := w.Buffer.Write(p[:len(p)/2])
+		return n, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestHandleEAGAIN(t *testing.T) {
+	t.Run("retries after EAGAIN", func(t *testing.T) {
+		if errWouldBlock == nil {
+			t.Skip("no EAGAIN on this platform")
+		}
+		dst := &eagainWriter{err: fmt.Errorf("write: %w", errWouldBlock)}
+
+		n, err := Copy(context.Background(), dst, strings.NewReader("hello world"), HandleEAGAIN(true))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 || dst.String() != "hello world" {
+			t.Fatalf("expected all data to be written once but got %d bytes: %q", n, dst.String())
+		}
+	})
+
+	t.Run("waits for readiness with a custom sentinel", func(t *testing.T) {
+		errBusy := errors.New("busy")
+		dst := &eagainWriter{err: errBusy}
+
+		var waits int
+		_, err := Copy(
+			context.Background(),
+			dst,
+			strings.NewReader("hello world"),
+			HandleEAGAIN(true),
+			WouldBlockError(errBusy),
+			WaitWritable(func(ctx context.Context) error {
+				waits++
+				return nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if waits != 1 {
+			t.Fatalf("expected to wait for readiness once but waited %d times", waits)
+		}
+		if dst.String() != "hello world" {
+			t.Fatalf("expected dst to be %q but got %q", "hello world", dst.String())
+		}
+	})
+
+	t.Run("fails without the option", func(t *testing.T) {
+		if errWouldBlock == nil {
+			t.Skip("no EAGAIN on this platform")
+		}
+		dst := &eagainWriter{err: errWouldBlock}
+
+		n, err := Copy(context.Background(), dst, strings.NewReader("hello world"))
+		if !errors.Is(err, errWouldBlock) {
+			t.Fatalf("expected err to be %#q but got %#q", errWouldBlock, err)
+		}
+		if n != 5 {
+			t.Fatalf("expected n to be 5 but got %d", n)
+		}
+	})
+}
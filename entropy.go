@@ -0,0 +1,24 @@
+package xio
+
+import "math"
+
+// entropy returns the Shannon entropy in bits per byte of the distribution described by histogram.
+func entropy(histogram *[256]int64) float64 {
+	var total int64
+	for _, count := range histogram {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	var bits float64
+	for _, count := range histogram {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(total)
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
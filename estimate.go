@@ -0,0 +1,39 @@
+package xio
+
+import (
+	"context"
+	"io"
+)
+
+// estimateCap is the highest percentage reported by CopyWithEstimate before src reaches EOF.
+const estimateCap = 99
+
+// CopyWithEstimate copies src into dst like Copy, calling fn after every chunk with the percentage of estimate bytes
+// written so far, for progress bars over sources that don't report their size. Since the estimate may be off, the
+// percentage is capped at 99 until src reaches EOF, at which point fn is called once more with 100, however many bytes
+// were actually copied. It is not called with 100 if the copy fails. A Progress option given along is still called.
+func CopyWithEstimate(ctx context.Context, dst io.Writer, src io.Reader, estimate int64, fn func(pct float64), opts ...CopyOption) (int64, error) {
+	report := func(c *copyoptions) {
+		progress := c.progress
+		c.progress = func(written, total int64) {
+			if progress != nil {
+				progress(written, total)
+			}
+
+			var pct float64
+			if estimate > 0 {
+				pct = 100 * float64(written) / float64(estimate)
+			}
+			if pct > estimateCap {
+				pct = estimateCap
+			}
+			fn(pct)
+		}
+	}
+
+	n, err := Copy(ctx, dst, src, append(opts, report)...)
+	if err == nil {
+		fn(100)
+	}
+	return n, err
+}
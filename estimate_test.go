@@ -0,0 +1,49 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCopyWithEstimate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		size     int
+		estimate int64
+		expected []float64
+	}{
+		{name: "accurate", size: 40, estimate: 40, expected: []float64{25, 50, 75, 99, 100}},
+		{name: "exceeds the estimate", size: 40, estimate: 20, expected: []float64{50, 99, 99, 99, 100}},
+		{name: "falls short of the estimate", size: 20, estimate: 40, expected: []float64{25, 50, 100}},
+		{name: "no estimate", size: 20, estimate: 0, expected: []float64{0, 0, 100}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var reported []float64
+			var progress int
+			n, err := CopyWithEstimate(
+				context.Background(),
+				io.Discard,
+				strings.NewReader(strings.Repeat("x", tc.size)),
+				tc.estimate,
+				func(pct float64) { reported = append(reported, pct) },
+				BufferSize(10),
+				Progress(func(written, total int64) { progress++ }),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != int64(tc.size) {
+				t.Fatalf("expected n to be %d but got %d", tc.size, n)
+			}
+			if !reflect.DeepEqual(reported, tc.expected) {
+				t.Fatalf("expected percentages %v but got %v", tc.expected, reported)
+			}
+			if progress != len(tc.expected)-1 {
+				t.Fatalf("expected progress option to be called %d times but got %d", len(tc.expected)-1, progress)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLengthMismatch is returned by CopyExpect when the number of bytes copied differs from the expected length.
+var ErrLengthMismatch = errors.New("length mismatch")
+
+// CopyExpect copies src into dst like Copy, and once src reaches EOF checks that exactly expected bytes were written,
+// for example against a declared Content-Length. Otherwise it fails with an error wrapping ErrLengthMismatch, along
+// with the number of bytes written. The check is skipped when the copy fails or is canceled, in which case the error
+// is returned as is.
+func CopyExpect(ctx context.Context, dst io.Writer, src io.Reader, expected int64, opts ...CopyOption) (int64, error) {
+	n, err := Copy(ctx, dst, src, opts...)
+	if err == nil && n != expected {
+		err = fmt.Errorf("%w: expected %d bytes but copied %d", ErrLengthMismatch, expected, n)
+	}
+	return n, err
+}
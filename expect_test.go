@@ -0,0 +1,47 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyExpect(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		src      string
+		expected int64
+		err      error
+	}{
+		{name: "matching", src: "hello world", expected: 11},
+		{name: "short", src: "hello", expected: 11, err: ErrLengthMismatch},
+		{name: "over length", src: "hello world!!", expected: 11, err: ErrLengthMismatch},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var dst bytes.Buffer
+			n, err := CopyExpect(context.Background(), &dst, strings.NewReader(tc.src), tc.expected, BufferSize(4))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected err to be %#q but got %#q", tc.err, err)
+			}
+			if n != int64(len(tc.src)) || dst.String() != tc.src {
+				t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+			}
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := CopyExpect(ctx, &bytes.Buffer{}, ReaderFunc(func(b []byte) (int, error) {
+			time.Sleep(5 * time.Millisecond)
+			return copy(b, "a"), nil
+		}), 1)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package xio
+
+import (
+	"context"
+	"io"
+)
+
+// CopyWithFallback copies from primary into dst, switching transparently to secondary if primary returns an error
+// before any bytes have been read from it. Once bytes have flowed from primary, any failure is fatal and returned as is,
+// since resuming from secondary could duplicate data in dst. An immediate io.EOF from primary is not considered a failure,
+// the source is simply empty. The same options as Copy can be passed to CopyWithFallback.
+func CopyWithFallback(ctx context.Context, dst io.Writer, primary, secondary io.Reader, opts ...CopyOption) (int64, error) {
+	return Copy(ctx, dst, &fallbackReader{primary: primary, secondary: secondary}, opts...)
+}
+
+type fallbackReader struct {
+	primary   io.Reader
+	secondary io.Reader
+	flowed    bool
+	switched  bool
+}
+
+func (r *fallbackReader) Read(p []byte) (int, error) {
+	if r.switched {
+		return r.secondary.Read(p)
+	}
+
+	n, err := r.primary.Read(p)
+	if n > 0 {
+		r.flowed = true
+	}
+	if err != nil && err != io.EOF && !r.flowed {
+		r.switched = true
+		return r.secondary.Read(p)
+	}
+	return n, err
+}
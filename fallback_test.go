@@ -0,0 +1,78 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCopyWithFallback(t *testing.T) {
+	primaryErr := errors.New("primary unavailable")
+
+	t.Run("switches to secondary when primary fails immediately", func(t *testing.T) {
+		var dst bytes.Buffer
+
+		n, err := CopyWithFallback(
+			context.Background(),
+			&dst,
+			ReaderFunc(func(b []byte) (int, error) { return 0, primaryErr }),
+			strings.NewReader("hello world"),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 {
+			t.Fatalf("expected n to be 11 but got %d", n)
+		}
+		if actual := dst.String(); actual != "hello world" {
+			t.Fatalf("expected dst to contain %q but got %q", "hello world", actual)
+		}
+	})
+
+	t.Run("failure after data has flowed is fatal", func(t *testing.T) {
+		var dst bytes.Buffer
+		var calls int
+
+		n, err := CopyWithFallback(
+			context.Background(),
+			&dst,
+			ReaderFunc(func(b []byte) (int, error) {
+				calls++
+				if calls == 1 {
+					return copy(b, "hello"), nil
+				}
+				return 0, primaryErr
+			}),
+			ReaderFunc(func(b []byte) (int, error) {
+				t.Error("secondary should not be read")
+				return 0, nil
+			}),
+		)
+		if err != primaryErr {
+			t.Fatalf("expected err to be %#q but got %#q", primaryErr, err)
+		}
+		if n != 5 {
+			t.Fatalf("expected n to be 5 but got %d", n)
+		}
+	})
+
+	t.Run("empty primary does not fall back", func(t *testing.T) {
+		n, err := CopyWithFallback(
+			context.Background(),
+			&bytes.Buffer{},
+			strings.NewReader(""),
+			ReaderFunc(func(b []byte) (int, error) {
+				t.Error("secondary should not be read")
+				return 0, nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 0 {
+			t.Fatalf("expected n to be 0 but got %d", n)
+		}
+	})
+}
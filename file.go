@@ -0,0 +1,47 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CopyToFile durably copies src to the file at path. The data is written to path+".tmp", synced to disk, and only then
+// renamed to path, so that path either holds the complete copy or is left untouched. The temporary file is removed if
+// the copy fails or is canceled. Since the temporary file may only be removed once the copy goroutine is done with it,
+// CopyToFile always waits for the last operation, regardless of WaitForLastOp.
+func CopyToFile(ctx context.Context, path string, src io.Reader, opts ...CopyOption) (n int64, err error) {
+	tmp := path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	if n, err = Copy(ctx, f, src, append(opts, WaitForLastOp(true))...); err != nil {
+		return n, err
+	}
+	if err = f.Sync(); err != nil {
+		return n, err
+	}
+	if err = f.Close(); err != nil {
+		return n, err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return n, err
+	}
+
+	// Syncing the directory persists the rename. Not every platform supports it, so it is done on a best effort basis.
+	if dir, dirErr := os.Open(filepath.Dir(path)); dirErr == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return n, nil
+}
@@ -0,0 +1,94 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyToFile(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.txt")
+
+		n, err := CopyToFile(context.Background(), path, strings.NewReader("durable data"))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 12 {
+			t.Fatalf("expected n to be 12 but got %d", n)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(data) != "durable data" {
+			t.Fatalf("expected file to contain %q but got %q", "durable data", data)
+		}
+		if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+			t.Fatalf("expected temp file to be gone but got %v", err)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.txt")
+		readErr := errors.New("reader broke!")
+
+		_, err := CopyToFile(
+			context.Background(),
+			path,
+			ReaderFunc(func(b []byte) (int, error) {
+				if _, err := os.Stat(path + ".tmp"); err != nil {
+					t.Errorf("expected data to be written to the temp file but got %v", err)
+				}
+				return copy(b, "partial"), readErr
+			}),
+		)
+		if err != readErr {
+			t.Fatalf("expected err to be %#q but got %#q", readErr, err)
+		}
+
+		for _, p := range []string{path, path + ".tmp"} {
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				t.Fatalf("expected %s not to exist but got %v", filepath.Base(p), err)
+			}
+		}
+	})
+
+	t.Run("existing target is untouched on cancelation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.txt")
+		if err := os.WriteFile(path, []byte("previous"), 0o666); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, err := CopyToFile(
+			ctx,
+			path,
+			ReaderFunc(func(b []byte) (int, error) {
+				cancel()
+				return copy(b, "new"), nil
+			}),
+			WaitForLastOp(false),
+		)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected error to be context canceled but got %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(data) != "previous" {
+			t.Fatalf("expected file to be untouched but got %q", data)
+		}
+		if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+			t.Fatalf("expected temp file to be gone but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package xio
+
+import "io"
+
+// FlakyReader returns a reader that simulates intermittent connectivity for testing retry logic deterministically.
+// Every Read consumes the next entry of pattern, cycling through it: a true entry makes the Read fail with retryErr
+// without reading from r, a false entry reads from r. An empty pattern never fails.
+func FlakyReader(r io.Reader, pattern []bool, retryErr error) io.Reader {
+	return &flakyReader{r: r, pattern: pattern, err: retryErr}
+}
+
+type flakyReader struct {
+	r       io.Reader
+	pattern []bool
+	err     error
+	reads   int
+}
+
+func (fr *flakyReader) Read(p []byte) (int, error) {
+	if len(fr.pattern) > 0 {
+		fail := fr.pattern[fr.reads%len(fr.pattern)]
+		fr.reads++
+		if fail {
+			return 0, fr.err
+		}
+	}
+	return fr.r.Read(p)
+}
@@ -0,0 +1,71 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlakyReader(t *testing.T) {
+	errFlaky := errors.New("connection reset")
+
+	t.Run("fails following the pattern", func(t *testing.T) {
+		r := FlakyReader(strings.NewReader("abcdef"), []bool{false, true, true}, errFlaky)
+
+		var results []string
+		for i := 0; i < 6; i++ {
+			b := make([]byte, 2)
+			n, err := r.Read(b)
+			if err != nil {
+				results = append(results, err.Error())
+				continue
+			}
+			results = append(results, string(b[:n]))
+		}
+
+		expected := []string{"ab", "connection reset", "connection reset", "cd", "connection reset", "connection reset"}
+		if !reflect.DeepEqual(results, expected) {
+			t.Fatalf("expected reads to be %q but got %q", expected, results)
+		}
+	})
+
+	t.Run("drives retries", func(t *testing.T) {
+		var retries int
+
+		var dst bytes.Buffer
+		n, err := Copy(
+			context.Background(),
+			&dst,
+			FlakyReader(strings.NewReader("hello world"), []bool{true, false, true, true, false}, errFlaky),
+			BufferSize(4),
+			RetryAfter(func(err error) (time.Duration, bool) {
+				if !errors.Is(err, errFlaky) {
+					return 0, false
+				}
+				retries++
+				return time.Millisecond, true
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 || dst.String() != "hello world" {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+		}
+		// reads: fail, "hell", fail, fail, "o wo", fail, "rld", fail, fail, EOF
+		if retries != 6 {
+			t.Fatalf("expected 6 retries but got %d", retries)
+		}
+	})
+
+	t.Run("without retries the failure aborts the copy", func(t *testing.T) {
+		_, err := Copy(context.Background(), &bytes.Buffer{}, FlakyReader(strings.NewReader("hello"), []bool{true}, errFlaky))
+		if err != errFlaky {
+			t.Fatalf("expected err to be %#q but got %#q", errFlaky, err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package xio
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// FramedWriter returns a writer that prefixes every Write to w with a 4 byte length header encoded with byteOrder,
+// so that copying through it produces a length delimited stream that can be read back with ReadFrame. The count
+// returned by Write only includes the payload, so that a Copy into a FramedWriter reports the number of bytes
+// copied from src, and not the size of the framed stream.
+func FramedWriter(w io.Writer, byteOrder binary.ByteOrder) io.Writer {
+	return &framedWriter{w: w, order: byteOrder}
+}
+
+type framedWriter struct {
+	w     io.Writer
+	order binary.ByteOrder
+	frame []byte
+}
+
+func (fw *framedWriter) Write(p []byte) (int, error) {
+	fw.frame = append(fw.frame[:0], 0, 0, 0, 0)
+	fw.order.PutUint32(fw.frame, uint32(len(p)))
+	fw.frame = append(fw.frame, p...)
+
+	n, err := fw.w.Write(fw.frame)
+	if n -= 4; n < 0 {
+		n = 0
+	}
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// ReadFrame reads a single frame written by a FramedWriter from r and returns its payload. It returns io.EOF if r
+// is exhausted before a frame starts, and io.ErrUnexpectedEOF if it ends within a frame.
+func ReadFrame(r io.Reader, byteOrder binary.ByteOrder) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, byteOrder.Uint32(header[:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,58 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFramedWriter(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		t.Run(order.String(), func(t *testing.T) {
+			var stream bytes.Buffer
+
+			n, err := Copy(context.Background(), FramedWriter(&stream, order), strings.NewReader("hello framed world"), BufferSize(6))
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != 18 {
+				t.Fatalf("expected n to count the 18 payload bytes but got %d", n)
+			}
+			if stream.Len() != 18+3*4 {
+				t.Fatalf("expected stream to hold 3 frames of 6 bytes but got %d bytes", stream.Len())
+			}
+
+			var frames []string
+			for {
+				frame, err := ReadFrame(&stream, order)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("expected err to be nil but got %#q", err)
+				}
+				frames = append(frames, string(frame))
+			}
+
+			if expected := []string{"hello ", "framed", " world"}; !reflect.DeepEqual(frames, expected) {
+				t.Fatalf("expected frames to be %q but got %q", expected, frames)
+			}
+		})
+	}
+
+	t.Run("truncated frame", func(t *testing.T) {
+		var stream bytes.Buffer
+		if _, err := FramedWriter(&stream, binary.BigEndian).Write([]byte("payload")); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		stream.Truncate(stream.Len() - 1)
+
+		if _, err := ReadFrame(&stream, binary.BigEndian); err != io.ErrUnexpectedEOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrUnexpectedEOF, err)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package xio
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrChunkAuth is returned by a GCMChunkReader when a chunk fails authentication, because it was tampered with,
+// reordered, truncated or encrypted with another key.
+var ErrChunkAuth = errors.New("chunk authentication failed")
+
+// GCMChunkWriter returns a writer that encrypts data written to it with aead, typically AES-GCM, in chunks of
+// chunkSize bytes of plaintext. Each chunk is written to w as a frame holding a 4 byte big endian length followed by
+// a random nonce and the ciphertext. The index of the chunk and whether it is the last one are authenticated along with
+// it, so that reordered, dropped or truncated chunks are detected by GCMChunkReader. Close must be called to write the
+// last chunk. It does not close w. A non-positive chunkSize makes every Write and Close fail with an error.
+func GCMChunkWriter(w io.Writer, aead cipher.AEAD, chunkSize int) io.WriteCloser {
+	gw := &gcmChunkWriter{w: w, aead: aead, size: chunkSize}
+	if chunkSize <= 0 {
+		gw.err = fmt.Errorf("xio: GCMChunkWriter: invalid chunk size %d", chunkSize)
+	}
+	return gw
+}
+
+type gcmChunkWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	size  int
+	index uint64
+	buf   []byte
+	frame []byte
+	err   error
+}
+
+func (gw *gcmChunkWriter) Write(p []byte) (int, error) {
+	if gw.err != nil {
+		return 0, gw.err
+	}
+
+	var written int
+	for len(p) > 0 {
+		n := gw.size - len(gw.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		gw.buf = append(gw.buf, p[:n]...)
+		p = p[n:]
+
+		if len(gw.buf) == gw.size {
+			if gw.err = gw.seal(false); gw.err != nil {
+				return written, gw.err
+			}
+		}
+		written += n
+	}
+	return written, nil
+}
+
+func (gw *gcmChunkWriter) Close() error {
+	if gw.err != nil {
+		return gw.err
+	}
+	gw.err = gw.seal(true)
+	if gw.err == nil {
+		gw.err = errors.New("xio: write to closed GCMChunkWriter")
+		return nil
+	}
+	return gw.err
+}
+
+// seal encrypts the buffered plaintext and writes it to w as a single frame.
+func (gw *gcmChunkWriter) seal(last bool) error {
+	nonceSize := gw.aead.NonceSize()
+
+	gw.frame = append(gw.frame[:0], make([]byte, 4+nonceSize)...)
+	if _, err := io.ReadFull(rand.Reader, gw.frame[4:]); err != nil {
+		return err
+	}
+	gw.frame = gw.aead.Seal(gw.frame, gw.frame[4:], gw.buf, gcmChunkAD(gw.index, last))
+	binary.BigEndian.PutUint32(gw.frame, uint32(len(gw.frame)-4))
+
+	if _, err := gw.w.Write(gw.frame); err != nil {
+		return err
+	}
+	gw.index++
+	gw.buf = gw.buf[:0]
+	return nil
+}
+
+// GCMChunkReader returns a reader that decrypts and authenticates the chunks written by a GCMChunkWriter using the
+// same aead and chunkSize, streaming the plaintext. A chunk that fails authentication, or a stream that ends before its
+// last chunk, results in ErrChunkAuth. Plaintext is only returned once its chunk has been authenticated. A
+// non-positive chunkSize makes every Read fail with an error.
+func GCMChunkReader(r io.Reader, aead cipher.AEAD, chunkSize int) io.Reader {
+	gr := &gcmChunkReader{r: r, aead: aead, size: chunkSize}
+	if chunkSize <= 0 {
+		gr.err = fmt.Errorf("xio: GCMChunkReader: invalid chunk size %d", chunkSize)
+	}
+	return gr
+}
+
+type gcmChunkReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	size    int
+	index   uint64
+	frame   []byte
+	plain   []byte
+	pending []byte
+	last    bool
+	err     error
+}
+
+func (gr *gcmChunkReader) Read(p []byte) (int, error) {
+	for len(gr.pending) == 0 {
+		if gr.err != nil {
+			return 0, gr.err
+		}
+		gr.err = gr.open()
+	}
+	n := copy(p, gr.pending)
+	gr.pending = gr.pending[n:]
+	return n, nil
+}
+
+// open reads the next frame from r and decrypts it into pending.
+func (gr *gcmChunkReader) open() error {
+	var header [4]byte
+	if _, err := io.ReadFull(gr.r, header[:]); err != nil {
+		if err == io.EOF && gr.last {
+			return io.EOF
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrChunkAuth
+		}
+		return err
+	}
+	if gr.last {
+		return ErrChunkAuth
+	}
+
+	nonceSize := gr.aead.NonceSize()
+	size := int(binary.BigEndian.Uint32(header[:]))
+	if size < nonceSize+gr.aead.Overhead() || size > nonceSize+gr.size+gr.aead.Overhead() {
+		return ErrChunkAuth
+	}
+
+	if cap(gr.frame) < size {
+		gr.frame = make([]byte, size)
+	}
+	gr.frame = gr.frame[:size]
+	if _, err := io.ReadFull(gr.r, gr.frame); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrChunkAuth
+		}
+		return err
+	}
+
+	nonce, ciphertext := gr.frame[:nonceSize], gr.frame[nonceSize:]
+	// Whether a chunk is the last one is only known by authenticating it as such. Open clears its output on failure,
+	// so the ciphertext is not decrypted in place to be able to try both.
+	plaintext, err := gr.aead.Open(gr.plain[:0], nonce, ciphertext, gcmChunkAD(gr.index, false))
+	if err != nil {
+		if plaintext, err = gr.aead.Open(gr.plain[:0], nonce, ciphertext, gcmChunkAD(gr.index, true)); err != nil {
+			return ErrChunkAuth
+		}
+		gr.last = true
+	}
+
+	gr.index++
+	gr.plain = plaintext
+	gr.pending = plaintext
+	return nil
+}
+
+// gcmChunkAD returns the additional data authenticated with a chunk: its index and whether it is the last one.
+func gcmChunkAD(index uint64, last bool) []byte {
+	ad := make([]byte, 9)
+	binary.BigEndian.PutUint64(ad, index)
+	if last {
+		ad[8] = 1
+	}
+	return ad
+}
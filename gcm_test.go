@@ -0,0 +1,101 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"strings"
+	"testing"
+)
+
+func TestGCMChunkReader(t *testing.T) {
+	block, err := aes.NewCipher(bytes.Repeat([]byte{42}, 32))
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+
+	encrypt := func(t *testing.T, plaintext string) []byte {
+		var encrypted bytes.Buffer
+		w := GCMChunkWriter(&encrypted, aead, 8)
+		if _, err := Copy(context.Background(), w, strings.NewReader(plaintext), BufferSize(5)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		return encrypted.Bytes()
+	}
+
+	frameSize := 4 + aead.NonceSize() + 8 + aead.Overhead()
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, plaintext := range []string{"", "exactly!", "streaming authenticated decryption"} {
+			var dst bytes.Buffer
+			n, err := Copy(context.Background(), &dst, GCMChunkReader(bytes.NewReader(encrypt(t, plaintext)), aead, 8), BufferSize(3))
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != int64(len(plaintext)) || dst.String() != plaintext {
+				t.Fatalf("expected %q to be decrypted but got %d bytes: %q", plaintext, n, dst.String())
+			}
+		}
+	})
+
+	t.Run("tampering", func(t *testing.T) {
+		encrypted := encrypt(t, "streaming authenticated decryption")
+
+		flipped := append([]byte{}, encrypted...)
+		flipped[frameSize+20] ^= 1
+
+		reordered := append([]byte{}, encrypted[frameSize:2*frameSize]...)
+		reordered = append(reordered, encrypted[:frameSize]...)
+		reordered = append(reordered, encrypted[2*frameSize:]...)
+
+		for _, tc := range []struct {
+			name      string
+			encrypted []byte
+			expected  string
+		}{
+			{name: "flipped bit", encrypted: flipped, expected: "streamin"},
+			{name: "reordered chunks", encrypted: reordered},
+			{name: "dropped last chunk", encrypted: encrypted[:4*frameSize], expected: "streaming authenticated decrypti"},
+			{name: "truncated chunk", encrypted: encrypted[:len(encrypted)-1], expected: "streaming authenticated decrypti"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				var dst bytes.Buffer
+				_, err := Copy(context.Background(), &dst, GCMChunkReader(bytes.NewReader(tc.encrypted), aead, 8))
+				if err != ErrChunkAuth {
+					t.Fatalf("expected err to be %#q but got %#q", ErrChunkAuth, err)
+				}
+				if dst.String() != tc.expected {
+					t.Fatalf("expected only authenticated chunks %q to be copied but got %q", tc.expected, dst.String())
+				}
+			})
+		}
+	})
+	t.Run("invalid chunk size", func(t *testing.T) {
+		for _, size := range []int{0, -1} {
+			var encrypted bytes.Buffer
+			w := GCMChunkWriter(&encrypted, aead, size)
+			if _, err := w.Write([]byte("hello")); err == nil {
+				t.Fatalf("expected Write to fail for a chunk size of %d", size)
+			}
+			if err := w.Close(); err == nil {
+				t.Fatalf("expected Close to fail for a chunk size of %d", size)
+			}
+			if encrypted.Len() != 0 {
+				t.Fatalf("expected nothing to be written for a chunk size of %d but got %d bytes", size, encrypted.Len())
+			}
+
+			n, err := Copy(context.Background(), &bytes.Buffer{}, GCMChunkReader(bytes.NewReader(encrypt(t, "hello")), aead, size))
+			if err == nil || n != 0 {
+				t.Fatalf("expected Read to fail for a chunk size of %d but got %d bytes and %#q", size, n, err)
+			}
+		}
+	})
+}
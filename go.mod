@@ -1,3 +1,7 @@
 module github.com/davidmdm/xio
 
-go 1.19
+go 1.21
+
+require golang.org/x/text v0.14.0
+
+require golang.org/x/sys v0.15.0
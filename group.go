@@ -0,0 +1,22 @@
+package xio
+
+import (
+	"context"
+	"io"
+)
+
+// DeadlineGroup runs copies that share the deadline and cancelation of a single context, giving a batch of
+// copies one collective time budget.
+type DeadlineGroup struct {
+	ctx context.Context
+}
+
+// NewDeadlineGroup returns a DeadlineGroup whose copies are bound to ctx.
+func NewDeadlineGroup(ctx context.Context) *DeadlineGroup {
+	return &DeadlineGroup{ctx: ctx}
+}
+
+// Copy behaves like xio.Copy using the group's context.
+func (g *DeadlineGroup) Copy(dst io.Writer, src io.Reader, opts ...CopyOption) (int64, error) {
+	return Copy(g.ctx, dst, src, opts...)
+}
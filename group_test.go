@@ -0,0 +1,64 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeadlineGroup(t *testing.T) {
+	t.Run("all copies abort when the shared context cancels", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		group := NewDeadlineGroup(ctx)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 3)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = group.Copy(
+					io.Discard,
+					ReaderFunc(func(b []byte) (int, error) {
+						time.Sleep(time.Millisecond)
+						return len(b), nil
+					}),
+				)
+			}(i)
+		}
+
+		time.AfterFunc(10*time.Millisecond, cancel)
+		wg.Wait()
+
+		for i, err := range errs {
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected copy %d to be canceled but got %v", i, err)
+			}
+		}
+	})
+
+	t.Run("copies share the deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		group := NewDeadlineGroup(ctx)
+
+		if _, err := group.Copy(io.Discard, ReaderFunc(func(b []byte) (int, error) {
+			time.Sleep(15 * time.Millisecond)
+			return len(b), io.EOF
+		})); err != nil {
+			t.Fatalf("expected first copy to succeed but got %v", err)
+		}
+
+		_, err := group.Copy(io.Discard, ReaderFunc(func(b []byte) (int, error) {
+			time.Sleep(time.Millisecond)
+			return len(b), nil
+		}))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected second copy to exceed the shared deadline but got %v", err)
+		}
+	})
+}
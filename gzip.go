@@ -0,0 +1,49 @@
+package xio
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+)
+
+// GzipMemberReader returns a reader that decompresses exactly one gzip member from r and then reports io.EOF.
+// Subsequent members are left unread in r so that a new GzipMemberReader can process them, allowing concatenated
+// gzip streams to be handled member by member. If r does not implement io.ByteReader it is read one byte at a time,
+// since buffering would consume bytes belonging to the next member.
+func GzipMemberReader(r io.Reader) io.Reader {
+	return &gzipMemberReader{r: r}
+}
+
+type gzipMemberReader struct {
+	r  io.Reader
+	zr *gzip.Reader
+}
+
+func (r *gzipMemberReader) Read(p []byte) (int, error) {
+	if r.zr == nil {
+		src, ok := r.r.(flate.Reader)
+		if !ok {
+			src = singleByteReader{r.r}
+		}
+		zr, err := gzip.NewReader(src)
+		if err != nil {
+			return 0, err
+		}
+		zr.Multistream(false)
+		r.zr = zr
+	}
+	return r.zr.Read(p)
+}
+
+// singleByteReader implements io.ByteReader without reading ahead of the requested byte.
+type singleByteReader struct {
+	io.Reader
+}
+
+func (r singleByteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r.Reader, b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
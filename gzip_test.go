@@ -0,0 +1,60 @@
+package xio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestGzipMemberReader(t *testing.T) {
+	var stream bytes.Buffer
+	for _, member := range []string{"first member", "second member"} {
+		zw := gzip.NewWriter(&stream)
+		if _, err := zw.Write([]byte(member)); err != nil {
+			t.Fatalf("failed to write gzip member: %v", err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("failed to close gzip member: %v", err)
+		}
+	}
+
+	t.Run("stops at member boundary", func(t *testing.T) {
+		src := bytes.NewReader(stream.Bytes())
+
+		for _, expected := range []string{"first member", "second member"} {
+			actual, err := io.ReadAll(GzipMemberReader(src))
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if string(actual) != expected {
+				t.Fatalf("expected member to be %q but got %q", expected, actual)
+			}
+		}
+
+		if src.Len() != 0 {
+			t.Fatalf("expected source to be fully consumed but %d bytes remain", src.Len())
+		}
+	})
+
+	t.Run("does not over read a source without ReadByte", func(t *testing.T) {
+		underlying := bytes.NewReader(stream.Bytes())
+		src := ReaderFunc(underlying.Read)
+
+		actual, err := io.ReadAll(GzipMemberReader(src))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(actual) != "first member" {
+			t.Fatalf("expected member to be %q but got %q", "first member", actual)
+		}
+
+		actual, err = io.ReadAll(GzipMemberReader(src))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(actual) != "second member" {
+			t.Fatalf("expected member to be %q but got %q", "second member", actual)
+		}
+	})
+}
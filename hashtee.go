@@ -0,0 +1,13 @@
+package xio
+
+import (
+	"hash"
+	"io"
+)
+
+// HashTeeReader returns a reader that writes to both w and h what it reads from r, so that a single pass feeds the
+// primary consumer, a side writer and a digest. Like io.TeeReader, a failed write to w is returned as a read error,
+// and there is no internal buffering: the write to w must complete before the Read returns. Writes to h never fail.
+func HashTeeReader(r io.Reader, w io.Writer, h hash.Hash) io.Reader {
+	return io.TeeReader(r, io.MultiWriter(h, w))
+}
@@ -0,0 +1,46 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashTeeReader(t *testing.T) {
+	t.Run("side writer and hash", func(t *testing.T) {
+		src := strings.Repeat("the quick brown fox jumps over the lazy dog\n", 100)
+
+		var dst, side bytes.Buffer
+		h := sha256.New()
+
+		n, err := Copy(context.Background(), &dst, HashTeeReader(strings.NewReader(src), &side, h), BufferSize(7))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != int64(len(src)) || dst.String() != src {
+			t.Fatalf("expected all data to be copied but got %d bytes", n)
+		}
+		if side.String() != src {
+			t.Fatalf("expected side writer to receive all data but got %d bytes", side.Len())
+		}
+
+		expected := sha256.Sum256([]byte(src))
+		if actual := h.Sum(nil); !bytes.Equal(actual, expected[:]) {
+			t.Fatalf("expected hash to be %x but got %x", expected, actual)
+		}
+	})
+
+	t.Run("side writer error", func(t *testing.T) {
+		writeErr := errors.New("side writer failed")
+
+		_, err := Copy(context.Background(), &bytes.Buffer{}, HashTeeReader(strings.NewReader("data"), WriterFunc(func(b []byte) (int, error) {
+			return 0, writeErr
+		}), sha256.New()))
+		if !errors.Is(err, writeErr) {
+			t.Fatalf("expected err to be %#q but got %#q", writeErr, err)
+		}
+	})
+}
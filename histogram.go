@@ -0,0 +1,46 @@
+package xio
+
+import (
+	"io"
+	"sync"
+)
+
+// HistWriter is a writer counting the frequency of every byte value written through it.
+type HistWriter struct {
+	w         io.Writer
+	mu        sync.Mutex
+	histogram [256]int64
+}
+
+// HistogramWriter returns a writer that writes to w while counting how many times every byte value is written, for
+// analysis of the data going through a copy, such as detecting data that is already compressed or encrypted. Only the
+// bytes accepted by w are counted. The histogram can be read while writes are in progress.
+func HistogramWriter(w io.Writer) *HistWriter {
+	return &HistWriter{w: w}
+}
+
+func (hw *HistWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+
+	hw.mu.Lock()
+	for _, b := range p[:n] {
+		hw.histogram[b]++
+	}
+	hw.mu.Unlock()
+
+	return n, err
+}
+
+// Histogram returns the number of times every byte value has been written, indexed by value.
+func (hw *HistWriter) Histogram() [256]int64 {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+	return hw.histogram
+}
+
+// Entropy returns the Shannon entropy of the bytes written so far in bits per byte, close to 8 for compressed or
+// encrypted data.
+func (hw *HistWriter) Entropy() float64 {
+	histogram := hw.Histogram()
+	return entropy(&histogram)
+}
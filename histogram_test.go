@@ -0,0 +1,68 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHistogramWriter(t *testing.T) {
+	t.Run("known distribution", func(t *testing.T) {
+		src := strings.Repeat("aab", 100) + "\x00\xff"
+
+		var dst bytes.Buffer
+		hw := HistogramWriter(&dst)
+		if _, err := Copy(context.Background(), hw, strings.NewReader(src), BufferSize(7)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if dst.String() != src {
+			t.Fatalf("expected data to be written through")
+		}
+
+		var expected [256]int64
+		expected['a'] = 200
+		expected['b'] = 100
+		expected[0x00] = 1
+		expected[0xff] = 1
+		if actual := hw.Histogram(); actual != expected {
+			for i := range expected {
+				if actual[i] != expected[i] {
+					t.Fatalf("expected count of byte %#x to be %d but got %d", i, expected[i], actual[i])
+				}
+			}
+		}
+	})
+
+	t.Run("uniform distribution", func(t *testing.T) {
+		src := make([]byte, 256*4)
+		for i := range src {
+			src[i] = byte(i)
+		}
+
+		hw := HistogramWriter(&bytes.Buffer{})
+		if _, err := Copy(context.Background(), hw, bytes.NewReader(src)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		for value, count := range hw.Histogram() {
+			if count != 4 {
+				t.Fatalf("expected byte %#x to be counted 4 times but got %d", value, count)
+			}
+		}
+		if entropy := hw.Entropy(); entropy != 8 {
+			t.Fatalf("expected entropy to be 8 but got %v", entropy)
+		}
+	})
+
+	t.Run("short write", func(t *testing.T) {
+		hw := HistogramWriter(WriterFunc(func(b []byte) (int, error) {
+			return 2, nil
+		}))
+		if n, _ := hw.Write([]byte("abcd")); n != 2 {
+			t.Fatalf("expected n to be 2 but got %d", n)
+		}
+		if histogram := hw.Histogram(); histogram['a'] != 1 || histogram['b'] != 1 || histogram['c'] != 0 {
+			t.Fatalf("expected only the written bytes to be counted")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package xio
+
+import (
+	"sync"
+	"time"
+)
+
+// idleFlusher flushes a Flusher once reads have been idle for a while, if data was written since the last flush.
+// It is armed before every read and disarmed after it, so that flushes never overlap writes.
+type idleFlusher struct {
+	flusher Flusher
+	d       time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+	armed bool
+	dirty bool
+	err   error
+}
+
+// written records that data was written since the last flush.
+func (f *idleFlusher) written() {
+	f.mu.Lock()
+	f.dirty = true
+	f.mu.Unlock()
+}
+
+func (f *idleFlusher) arm() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.dirty {
+		return
+	}
+	f.armed = true
+	if f.timer == nil {
+		f.timer = time.AfterFunc(f.d, f.flush)
+	} else {
+		f.timer.Reset(f.d)
+	}
+}
+
+// disarm prevents a pending flush and returns the error of a flush that happened while armed.
+func (f *idleFlusher) disarm() error {
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.armed = false
+	return f.err
+}
+
+func (f *idleFlusher) flush() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.armed {
+		return
+	}
+	f.armed, f.dirty = false, false
+	f.err = f.flusher.Flush()
+}
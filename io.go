@@ -5,12 +5,40 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"sync/atomic"
+	"time"
 )
 
 // errInvalidWrite means that a write returned an impossible count.
 var errInvalidWrite = errors.New("invalid write result")
 
+// ErrMaxBytesExceeded is returned when src holds more data than allowed by the MaxBytes option.
+var ErrMaxBytesExceeded = errors.New("max bytes exceeded")
+
+const (
+	defaultBufferSize      = 32 * 1024 // same as io/io.go
+	maxSuggestedBufferSize = 1024 * 1024
+)
+
+// BufferSizeSuggester is implemented by readers that know their optimal read size, such as decompressors.
+// When src implements it and no BufferSize option is given, Copy uses the suggested size for its buffer,
+// bounded to 1MiB.
+type BufferSizeSuggester interface {
+	SuggestBufferSize() int
+}
+
+// Flusher is implemented by writers that buffer data and can push it to their underlying sink on demand,
+// such as *bufio.Writer.
+type Flusher interface {
+	Flush() error
+}
+
+// Syncer is implemented by writers that can commit the data written to them to stable storage, such as *os.File.
+type Syncer interface {
+	Sync() error
+}
+
 // Copy attempts to copy all of src into dst. It uses a goroutine to do so, and will exit early if the context
 // given to it is canceled. If the context is canceled, Copy will wait for the current read/write cycle to end
 // then exit unless explicitly passed the option "WaitForLastOp(false)". If WaitForLastOp is false, Copy
@@ -19,22 +47,88 @@ var errInvalidWrite = errors.New("invalid write result")
 // write goroutine exits. Use WaitForLastOp(false) if src or dst is slow and you do not care about the total
 // amount of bytes written to dst if a cancelation occurs.
 func Copy(ctx context.Context, dst io.Writer, src io.Reader, opts ...CopyOption) (n int64, err error) {
-	err = ctx.Err()
-	if err != nil {
-		return
-	}
-
 	options := copyoptions{
 		WaitForLastOp: true,
 		buffer:        nil,
-		bufferSize:    32 * 1024, // same as io/io.go
+		bufferSize:    0,
 	}
 	for _, apply := range opts {
 		apply(&options)
 	}
 
 	var atomicN atomic.Int64
+
+	// done is closed once the copy goroutine has ended, it stays nil if Copy returns before starting it.
+	var done chan struct{}
+
+	if options.onComplete != nil {
+		defer func() {
+			if done == nil || options.WaitForLastOp {
+				options.onComplete(n, err)
+				return
+			}
+			go func(err error) {
+				<-done
+				options.onComplete(atomicN.Load(), err)
+			}(err)
+		}()
+	}
+
+	if options.logger != nil {
+		defer logCopy(ctx, options.logger, time.Now())(&n, &err)
+	}
+
+	if options.invalid != nil {
+		return 0, options.invalid
+	}
+
+	err = ctx.Err()
+	if err != nil {
+		return
+	}
+
+	releaseSlot := func() {}
+	if options.concurrent {
+		if releaseSlot, err = acquireConcurrent(ctx); err != nil {
+			return 0, err
+		}
+		// The copy goroutine releases the slot once it ends, unless Copy returns before starting it.
+		defer func() {
+			if done == nil {
+				releaseSlot()
+			}
+		}()
+	}
+
+	if options.bufferSize == 0 {
+		options.bufferSize = defaultBufferSize
+		if suggester, ok := src.(BufferSizeSuggester); ok {
+			if size := suggester.SuggestBufferSize(); size > maxSuggestedBufferSize {
+				options.bufferSize = maxSuggestedBufferSize
+			} else if size > 0 {
+				options.bufferSize = size
+			}
+		}
+	}
+
+	var offset int64
+	if options.loadOffset != nil {
+		if offset, err = options.loadOffset(); err != nil {
+			return 0, err
+		}
+		if err = skip(ctx, src, offset); err != nil {
+			return 0, err
+		}
+	}
+
+	// The size is taken before src is wrapped by the options.
+	total := int64(-1)
+	if options.progressPath != "" {
+		total = knownSize(src)
+	}
+
 	errCh := make(chan error, 1)
+	done = make(chan struct{})
 
 	if options.WaitForLastOp {
 		defer func() {
@@ -53,40 +147,266 @@ func Copy(ctx context.Context, dst io.Writer, src io.Reader, opts ...CopyOption)
 		}
 	}
 
+	if options.retryAfter != nil {
+		src = &retryAfterReader{ctx: ctx, r: src, extract: options.retryAfter}
+	}
+
+	if options.maxBytes > 0 {
+		src = &maxBytesReader{r: src, remaining: options.maxBytes}
+	}
+
+	if options.recoverPanics && options.readAhead > 0 {
+		// ReadAhead reads src on a goroutine of its own, where a panic would escape the copy goroutine.
+		src = panicSafeReader{r: src}
+	}
+
+	// A source handing out its data in place is read without a buffer, unless ReadAhead needs one to read ahead into.
+	chunks, _ := src.(chunkReader)
+	if options.readAhead > 0 {
+		chunks = nil
+	}
+
+	release := func() {}
+	if options.bufferPoolKey != nil && options.buffer == nil && chunks == nil {
+		options.buffer, release = bufferFromContext(ctx, options.bufferPoolKey, options.readAhead > 0)
+	}
+
+	if options.buffer != nil {
+		options.bufferSize = len(options.buffer)
+	}
+	if options.maxMemory > 0 {
+		options.fitMemory()
+	}
+
 	buf := options.buffer
-	if buf == nil {
+	if buf == nil && chunks == nil {
 		buf = make([]byte, options.bufferSize)
+	} else if len(buf) > options.bufferSize {
+		buf = buf[:options.bufferSize]
 	}
 
-	go func() {
-		defer close(errCh)
+	var flusher Flusher
+	if options.flushEveryChunk {
+		flusher, _ = dst.(Flusher)
+	}
+
+	congestion, _ := dst.(CongestionReporter)
+
+	// committed is the number of bytes written to dst known to be persisted.
+	var syncer Syncer
+	var committed int64
+	if options.watermark != nil {
+		syncer, _ = dst.(Syncer)
+	}
+
+	var idle *idleFlusher
+	if options.flushOnIdle > 0 {
+		if f, ok := dst.(Flusher); ok {
+			idle = &idleFlusher{flusher: f, d: options.flushOnIdle}
+		}
+	}
+
+	// Bytes are counted as they reach dst so that n reflects what was actually written to it,
+	// even when options transform the stream on its way there.
+	w, finish, closeSink := options.wrapWriter(ctx, dst, &atomicN)
+
+	start := time.Now()
+
+	readChunk := func() ([]byte, error) {
+		n, err := src.Read(buf)
+		return buf[:n], err
+	}
+	if chunks != nil {
+		readChunk = func() ([]byte, error) {
+			return chunks.readChunk(options.bufferSize)
+		}
+	}
+
+	var shared *sharedFlow
+	if options.sharedLimiter != nil {
+		shared = options.sharedLimiter.flow(options.priority)
+	}
+
+	var slowest *slowestOps
+	if options.slowestOp != nil {
+		slowest = &slowestOps{}
+	}
+
+	loop := func() error {
+		if options.readAhead > 0 {
+			ra := newReadAhead(ctx, src, buf, options.readAhead)
+			defer ra.close()
+			readChunk = ra.read
+		}
+
+		// read is the number of bytes read from src and handed to dst so far.
+		var read int64
 		for {
-			rn, rErr := src.Read(buf)
-			if rn > 0 {
-				wn, wErr := dst.Write(buf[:rn])
-				if wn < 0 || wn > rn {
-					errCh <- errInvalidWrite
-					return
+			if congestion != nil {
+				if err := waitUncongested(ctx, congestion); err != nil {
+					return err
+				}
+			}
+
+			if idle != nil {
+				idle.arm()
+			}
+			if options.hooks.BeforeRead != nil {
+				if err := options.hooks.BeforeRead(); err != nil {
+					return err
+				}
+			}
+			readStart := time.Now()
+			chunk, rErr := readChunk()
+			if slowest != nil {
+				slowest.since(&slowest.read, readStart)
+			}
+			if idle != nil {
+				if err := idle.disarm(); err != nil {
+					return err
+				}
+			}
+			if options.hooks.AfterRead != nil {
+				if err := options.hooks.AfterRead(chunk, rErr); err != nil {
+					return err
+				}
+			}
+
+			if rn := len(chunk); rn > 0 {
+				if options.onBufferFill != nil {
+					options.onBufferFill(float64(rn) / float64(options.bufferSize))
 				}
 
-				atomicN.Add(int64(wn))
+				if shared != nil {
+					if err := shared.wait(ctx, rn); err != nil {
+						return err
+					}
+				}
+
+				if options.hooks.BeforeWrite != nil {
+					if err := options.hooks.BeforeWrite(chunk); err != nil {
+						return err
+					}
+				}
+				writeStart := time.Now()
+				wn, wErr := w.Write(chunk)
+				if slowest != nil {
+					slowest.since(&slowest.write, writeStart)
+				}
+				if options.hooks.AfterWrite != nil {
+					if err := options.hooks.AfterWrite(chunk, wn, wErr); err != nil {
+						return err
+					}
+				}
+				if wn < 0 || wn > rn {
+					return errInvalidWrite
+				}
 
 				if wErr != nil {
-					errCh <- wErr
-					return
+					return wErr
+				}
+
+				if idle != nil {
+					idle.written()
+				}
+
+				if flusher != nil {
+					if err := flusher.Flush(); err != nil {
+						return err
+					}
+				}
+
+				if syncer != nil {
+					if written := atomicN.Load(); written > committed {
+						if err := syncer.Sync(); err != nil {
+							return err
+						}
+						committed = written
+						options.watermark(offset + committed)
+					}
+				}
+
+				read += int64(rn)
+				if options.saveOffset != nil {
+					if err := options.saveOffset(offset + read); err != nil {
+						return err
+					}
+				}
+
+				if options.progress != nil || options.predictSize != nil {
+					written, total := atomicN.Load(), int64(-1)
+					if options.predictSize != nil {
+						total = options.predictSize(written, float64(written)/time.Since(start).Seconds())
+					}
+					if options.progress != nil {
+						options.progress(written, total)
+					}
+				}
+			} else if options.allowEmptyFlush && rErr == nil && read > 0 {
+				if _, err := w.Write(chunk[:0]); err != nil {
+					return err
 				}
 			}
 
+			if rErr == io.EOF {
+				return finish()
+			}
 			if rErr != nil {
-				if rErr != io.EOF {
-					errCh <- rErr
-				}
-				return
+				return rErr
 			}
 			if err := ctx.Err(); err != nil {
-				errCh <- err
-				return
+				return err
+			}
+
+			if options.rate != nil {
+				if err := sleepUntil(ctx, start.Add(options.rate.elapsed(read))); err != nil {
+					return err
+				}
 			}
+
+			if options.chunkDelay > 0 {
+				if err := sleep(ctx, options.chunkDelay); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	unregister := func() {}
+	if options.register {
+		unregister = register(start, &atomicN)
+	}
+
+	var progress *progressFile
+	if options.progressPath != "" {
+		progress = startProgressFile(options.progressPath, options.progressEvery, total, &atomicN)
+	}
+
+	go func() {
+		defer close(done)
+		defer close(errCh)
+
+		var err error
+		if options.recoverPanics {
+			err = catchPanic(loop)
+		} else {
+			err = loop()
+		}
+		if slowest != nil {
+			slowest.report(options.slowestOp)
+		}
+		if closeErr := closeSink(); err == nil {
+			err = closeErr
+		}
+		if progress != nil {
+			progress.close()
+		}
+		release()
+		releaseSlot()
+		// The copy is unregistered before its outcome is sent so that it is gone by the time Copy returns.
+		unregister()
+		if err != nil {
+			errCh <- err
 		}
 	}()
 
@@ -98,6 +418,171 @@ func Copy(ctx context.Context, dst io.Writer, src io.Reader, opts ...CopyOption)
 	}
 }
 
+// skip advances src by offset bytes, seeking when src is an io.Seeker and discarding otherwise.
+func skip(ctx context.Context, src io.Reader, offset int64) error {
+	if offset == 0 {
+		return nil
+	}
+	if seeker, ok := src.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekCurrent)
+		return err
+	}
+	if _, err := CopyN(ctx, io.Discard, src, offset); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// maxBytesReader reads up to remaining bytes from r and fails with ErrMaxBytesExceeded if r holds more.
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (mr *maxBytesReader) Read(p []byte) (int, error) {
+	if mr.remaining < 0 {
+		return 0, ErrMaxBytesExceeded
+	}
+	if int64(len(p)) > mr.remaining+1 {
+		p = p[:mr.remaining+1]
+	}
+	n, err := mr.r.Read(p)
+	if int64(n) > mr.remaining {
+		n = int(mr.remaining)
+		mr.remaining = -1
+		return n, ErrMaxBytesExceeded
+	}
+	mr.remaining -= int64(n)
+	return n, err
+}
+
+// countWriter adds the number of bytes written to w to n, and passes them to the taps.
+type countWriter struct {
+	w    io.Writer
+	n    *atomic.Int64
+	taps []tap
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	if n < 0 || n > len(p) {
+		return 0, errInvalidWrite
+	}
+	cw.n.Add(int64(n))
+	for _, t := range cw.taps {
+		t.write(p[:n])
+	}
+	return n, err
+}
+
+// WriteBuffers writes bufs to the underlying writer, as a single vectored write when it supports it.
+func (cw *countWriter) WriteBuffers(bufs net.Buffers) (int64, error) {
+	// net.Buffers consumes its buffers as they are written, the chunks are kept for the taps.
+	chunks := append(net.Buffers(nil), bufs...)
+	n, err := writeBuffers(cw.w, bufs)
+	if n < 0 || n > bufferLen(chunks) {
+		return 0, errInvalidWrite
+	}
+	cw.n.Add(n)
+	for remaining := n; remaining > 0 && len(chunks) > 0; chunks = chunks[1:] {
+		chunk := chunks[0]
+		if int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+		for _, t := range cw.taps {
+			t.write(chunk)
+		}
+		remaining -= int64(len(chunk))
+	}
+	return n, err
+}
+
+// A tap is installed by copy options that observe the bytes written to dst. done is called once the copy completes
+// successfully and may return an error to fail it.
+type tap struct {
+	write func(p []byte)
+	done  func() error
+}
+
+// finisher is implemented by writers installed by copy options that hold back data until more is written to them.
+// Once src is exhausted finish writes out whatever remains.
+type finisher interface {
+	finish() error
+}
+
+// wrapWriter applies the writer options to dst, counting into n the bytes that reach it, or reach the sink
+// replacing it. finish is called once src is exhausted. It finishes the wrappers from the outermost inwards, so that
+// data flushed by one wrapper still flows through the ones beneath it, and then completes the taps. closeSink is
+// called once the copy ends, whatever the outcome.
+func (options copyoptions) wrapWriter(ctx context.Context, dst io.Writer, n *atomic.Int64) (w io.Writer, finish, closeSink func() error) {
+	taps := make([]tap, len(options.taps))
+	for i, newTap := range options.taps {
+		taps[i] = newTap()
+	}
+
+	var finishers []finisher
+
+	closeSink = func() error { return nil }
+
+	// A sink replaces dst altogether, it receives the bytes that would have been written to dst and is finished last.
+	// If it is an io.Closer it is closed once the copy ends, whatever the outcome.
+	if options.sink != nil {
+		dst = options.sink(dst)
+		if f, ok := dst.(finisher); ok {
+			finishers = append(finishers, f)
+		}
+		if c, ok := dst.(io.Closer); ok {
+			closeSink = c.Close
+		}
+	}
+
+	out := dst
+	if options.idempotentKey != nil {
+		if kw, ok := dst.(KeyedWriter); ok {
+			out = &keyedWriter{w: kw, key: options.idempotentKey}
+		}
+	}
+
+	w = &countWriter{w: out, n: n, taps: taps}
+	if options.stall != nil {
+		if conn, ok := dst.(writeDeadliner); ok {
+			w = &stallWriter{w: w, conn: conn, stall: *options.stall}
+		}
+	}
+	if options.wouldBlock.enabled {
+		w = &wouldBlockWriter{ctx: ctx, w: w, wouldBlock: options.wouldBlock}
+	}
+	if options.retryAfter != nil {
+		w = &retryAfterWriter{ctx: ctx, w: w, extract: options.retryAfter}
+	}
+	for _, wrap := range options.writers {
+		w = wrap(w)
+		if f, ok := w.(finisher); ok {
+			finishers = append(finishers, f)
+		}
+	}
+	finish = func() error {
+		for i := len(finishers) - 1; i >= 0; i-- {
+			if err := finishers[i].finish(); err != nil {
+				return err
+			}
+		}
+		for _, t := range taps {
+			if t.done == nil {
+				continue
+			}
+			if err := t.done(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return w, finish, closeSink
+}
+
 // CopyBuffer is like copy but allows you to specify the buffer to be used for copying. This is useful for reusing the same buffer
 // accross different copy operations. This method exists to correspond to the standard io.CopyBuffer func, however within xio it is simply
 // a convenience for the Buffer option: xio.Copy(ctx, dst, src, xio.Buffer(buffer))
@@ -109,7 +594,7 @@ func CopyBuffer(ctx context.Context, dst io.Writer, src io.Reader, buffer []byte
 func CopyN(ctx context.Context, dst io.Writer, src io.Reader, n int64, opts ...CopyOption) (written int64, err error) {
 	written, err = Copy(ctx, dst, io.LimitReader(src, n), opts...)
 	if written == n {
-		return n, nil
+		return n, drainRemainder(ctx, src, opts)
 	}
 	if written < n && err == nil {
 		// src stopped early; must have been EOF.
@@ -118,9 +603,101 @@ func CopyN(ctx context.Context, dst io.Writer, src io.Reader, n int64, opts ...C
 	return
 }
 
+// drainRemainder discards up to the number of bytes given by the DrainRemainder option from src. Reaching EOF is not an
+// error.
+func drainRemainder(ctx context.Context, src io.Reader, opts []CopyOption) error {
+	var options copyoptions
+	for _, apply := range opts {
+		apply(&options)
+	}
+	if options.drainMax <= 0 {
+		return nil
+	}
+
+	if _, err := Copy(ctx, io.Discard, io.LimitReader(src, options.drainMax)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteAll writes data to dst in chunks of the buffer size, like Copy does from a reader holding data, and is
+// cancelable via the context. The chunks are subslices of data handed to dst as is, without going through a buffer. The
+// same options as Copy can be passed to WriteAll, including WaitForLastOp. It returns the number of bytes written to
+// dst, and ctx.Err() without writing anything if the context is already canceled.
+func WriteAll(ctx context.Context, dst io.Writer, data []byte, opts ...CopyOption) (int64, error) {
+	return Copy(ctx, dst, &sliceReader{data: data}, opts...)
+}
+
+// chunkReader is implemented by sources that can hand out their data in place, in chunks of up to max bytes, sparing
+// Copy the copy into its buffer.
+type chunkReader interface {
+	readChunk(max int) ([]byte, error)
+}
+
+// sliceReader reads data, handing it out in place to Copy.
+type sliceReader struct {
+	data []byte
+}
+
+func (sr *sliceReader) Read(p []byte) (int, error) {
+	chunk, err := sr.readChunk(len(p))
+	return copy(p, chunk), err
+}
+
+func (sr *sliceReader) readChunk(max int) ([]byte, error) {
+	if len(sr.data) == 0 {
+		return nil, io.EOF
+	}
+	chunk := sr.data[:min(max, len(sr.data))]
+	sr.data = sr.data[len(chunk):]
+	return chunk, nil
+}
+
+// Len returns the number of bytes left to read, sizing the progress file.
+func (sr *sliceReader) Len() int {
+	return len(sr.data)
+}
+
 // ReadAll works like io.Readall but is cancelable via a context.
 func ReadAll(ctx context.Context, src io.Reader) ([]byte, error) {
 	var dst bytes.Buffer
 	_, err := Copy(ctx, &dst, src, WaitForLastOp(true))
 	return dst.Bytes(), err
 }
+
+// ReadFullN fills each buffer of bufs in order from r, like io.ReadFull does for a single buffer, and is cancelable
+// via the context. It returns the total number of bytes read. The error is io.EOF only if no bytes were read, and
+// io.ErrUnexpectedEOF if r ended before all buffers were filled.
+func ReadFullN(ctx context.Context, r io.Reader, bufs [][]byte) (int, error) {
+	var total int64
+	for _, b := range bufs {
+		total += int64(len(b))
+	}
+
+	n, err := CopyN(ctx, &scatterWriter{bufs: bufs}, r, total)
+	if err == io.EOF && n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return int(n), err
+}
+
+// scatterWriter writes sequentially across bufs.
+type scatterWriter struct {
+	bufs [][]byte
+}
+
+func (sw *scatterWriter) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > n && len(sw.bufs) > 0 {
+		c := copy(sw.bufs[0], p[n:])
+		sw.bufs[0] = sw.bufs[0][c:]
+		if len(sw.bufs[0]) == 0 {
+			sw.bufs = sw.bufs[1:]
+		}
+		n += c
+	}
+	if n < len(p) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
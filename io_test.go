@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -254,6 +255,158 @@ func TestCopyN(t *testing.T) {
 			t.Fatalf("expected n to be 0 but got %d", n)
 		}
 	})
+
+	t.Run("drain remainder", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			src      string
+			max      int64
+			leftover string
+		}{
+			{name: "within the cap", src: "headerbody", max: 10, leftover: ""},
+			{name: "beyond the cap", src: "headerbody and more", max: 4, leftover: " and more"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				src := strings.NewReader(tc.src)
+
+				var dst strings.Builder
+				n, err := CopyN(context.Background(), &dst, src, 6, DrainRemainder(tc.max))
+				if err != nil {
+					t.Fatalf("expected err to be nil but got %#q", err)
+				}
+				if n != 6 || dst.String() != "header" {
+					t.Fatalf("expected 6 bytes to be copied but got %d bytes: %q", n, dst.String())
+				}
+
+				leftover, _ := io.ReadAll(src)
+				if string(leftover) != tc.leftover {
+					t.Fatalf("expected %q to be left in src but got %q", tc.leftover, leftover)
+				}
+			})
+		}
+	})
+
+	t.Run("drain canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		n, err := CopyN(ctx, io.Discard, ReaderFunc(func(b []byte) (int, error) {
+			time.Sleep(5 * time.Millisecond)
+			return copy(b, "x"), nil
+		}), 1, DrainRemainder(1<<20))
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+		if n != 1 {
+			t.Fatalf("expected n to be 1 but got %d", n)
+		}
+	})
+}
+
+func TestWriteAll(t *testing.T) {
+	t.Run("writes in chunks", func(t *testing.T) {
+		var chunks []string
+		n, err := WriteAll(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				chunks = append(chunks, string(b))
+				return len(b), nil
+			}),
+			[]byte("hello world"),
+			BufferSize(4),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 {
+			t.Fatalf("expected n to be 11 but got %d", n)
+		}
+		if expected := []string{"hell", "o wo", "rld"}; !reflect.DeepEqual(chunks, expected) {
+			t.Fatalf("expected chunks %q but got %q", expected, chunks)
+		}
+	})
+
+	t.Run("writes subslices of the data", func(t *testing.T) {
+		data := []byte("hello world")
+		var offset int
+		if _, err := WriteAll(context.Background(), WriterFunc(func(b []byte) (int, error) {
+			if &b[0] != &data[offset] {
+				t.Errorf("expected a subslice of the data at offset %d", offset)
+			}
+			offset += len(b)
+			return len(b), nil
+		}), data, BufferSize(4)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if offset != len(data) {
+			t.Fatalf("expected %d bytes to be written but got %d", len(data), offset)
+		}
+	})
+
+	t.Run("options apply", func(t *testing.T) {
+		var dst bytes.Buffer
+		var completed int64
+		n, err := WriteAll(
+			context.Background(),
+			&dst,
+			[]byte("hello world"),
+			BufferSize(4),
+			ReadAhead(2),
+			OnComplete(func(n int64, err error) { completed = n }),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 || dst.String() != "hello world" {
+			t.Fatalf("expected data to be written but got %d bytes: %q", n, dst.String())
+		}
+		if completed != 11 {
+			t.Fatalf("expected OnComplete to be called with 11 but got %d", completed)
+		}
+	})
+
+	t.Run("already canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		n, err := WriteAll(ctx, WriterFunc(func(b []byte) (int, error) {
+			t.Errorf("expected nothing to be written")
+			return len(b), nil
+		}), []byte("hello"))
+		if err != context.Canceled {
+			t.Fatalf("expected err to be %#q but got %#q", context.Canceled, err)
+		}
+		if n != 0 {
+			t.Fatalf("expected n to be 0 but got %d", n)
+		}
+	})
+
+	t.Run("canceled while writing", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 45*time.Millisecond)
+		defer cancel()
+
+		n, err := WriteAll(ctx, WriterFunc(func(b []byte) (int, error) {
+			time.Sleep(30 * time.Millisecond)
+			return len(b), nil
+		}), make([]byte, 100), BufferSize(10))
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+		// the write in flight when the context is canceled is waited for
+		if n != 20 {
+			t.Fatalf("expected n to be 20 but got %d", n)
+		}
+	})
+
+	t.Run("empty data", func(t *testing.T) {
+		n, err := WriteAll(context.Background(), WriterFunc(func(b []byte) (int, error) {
+			t.Errorf("expected nothing to be written")
+			return len(b), nil
+		}), nil)
+		if err != nil || n != 0 {
+			t.Fatalf("expected nothing to be written and no error but got %d and %#q", n, err)
+		}
+	})
 }
 
 func TestCopyBuffer(t *testing.T) {
@@ -297,6 +450,77 @@ func TestReadAll(t *testing.T) {
 	}
 }
 
+func TestReadFullN(t *testing.T) {
+	t.Run("fills every buffer in order", func(t *testing.T) {
+		magic := make([]byte, 4)
+		version := make([]byte, 2)
+		length := make([]byte, 3)
+
+		n, err := ReadFullN(
+			context.Background(),
+			bytes.NewReader([]byte("XIO!v1abcrest")),
+			[][]byte{magic, version, length},
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 9 {
+			t.Fatalf("expected n to be 9 but got %d", n)
+		}
+
+		for _, tc := range []struct{ expected, actual string }{
+			{"XIO!", string(magic)},
+			{"v1", string(version)},
+			{"abc", string(length)},
+		} {
+			if tc.actual != tc.expected {
+				t.Fatalf("expected buffer to contain %q but got %q", tc.expected, tc.actual)
+			}
+		}
+	})
+
+	t.Run("short read", func(t *testing.T) {
+		n, err := ReadFullN(
+			context.Background(),
+			bytes.NewReader([]byte("XIO!v")),
+			[][]byte{make([]byte, 4), make([]byte, 2), make([]byte, 3)},
+		)
+		if err != io.ErrUnexpectedEOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrUnexpectedEOF, err)
+		}
+		if n != 5 {
+			t.Fatalf("expected n to be 5 but got %d", n)
+		}
+	})
+
+	t.Run("empty source", func(t *testing.T) {
+		n, err := ReadFullN(context.Background(), bytes.NewReader(nil), [][]byte{make([]byte, 4)})
+		if err != io.EOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.EOF, err)
+		}
+		if n != 0 {
+			t.Fatalf("expected n to be 0 but got %d", n)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, err := ReadFullN(
+			ctx,
+			ReaderFunc(func(b []byte) (int, error) {
+				cancel()
+				return 1, nil
+			}),
+			[][]byte{make([]byte, 4), make([]byte, 4)},
+		)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected error to be context canceled but got %v", err)
+		}
+	})
+}
+
 type ReaderFunc func([]byte) (int, error)
 
 func (fn ReaderFunc) Read(data []byte) (int, error) { return fn(data) }
@@ -0,0 +1,105 @@
+package xio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONValidatingWriter returns a writer that buffers each complete JSON object or array written to it, tracking
+// the nesting depth of braces and brackets, and forwards it to w only once it is well formed and validate accepts
+// it. Whitespace between values, such as the newlines of a JSON lines stream, is forwarded as is. A malformed value
+// aborts with a *json.SyntaxError, and a rejected value with the error returned by validate. The RawMessage passed to
+// validate is not reused by the writer.
+func JSONValidatingWriter(w io.Writer, validate func(json.RawMessage) error) io.Writer {
+	return &jsonValidatingWriter{w: w, validate: validate}
+}
+
+type jsonValidatingWriter struct {
+	w        io.Writer
+	validate func(json.RawMessage) error
+	out      []byte
+	value    []byte
+	depth    int
+	inString bool
+	escaped  bool
+	err      error
+}
+
+func (jw *jsonValidatingWriter) Write(p []byte) (int, error) {
+	if jw.err != nil {
+		return 0, jw.err
+	}
+
+	jw.out = jw.out[:0]
+	for i, c := range p {
+		if len(jw.value) == 0 {
+			switch c {
+			case ' ', '\t', '\r', '\n':
+				jw.out = append(jw.out, c)
+				continue
+			case '{', '[':
+			default:
+				return jw.fail(i, fmt.Errorf("invalid character %q outside of JSON object or array", c))
+			}
+		}
+
+		jw.value = append(jw.value, c)
+
+		switch {
+		case jw.escaped:
+			jw.escaped = false
+			continue
+		case jw.inString:
+			switch c {
+			case '\\':
+				jw.escaped = true
+			case '"':
+				jw.inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			jw.inString = true
+		case '{', '[':
+			jw.depth++
+		case '}', ']':
+			jw.depth--
+		}
+		if jw.depth > 0 {
+			continue
+		}
+
+		var value json.RawMessage
+		if err := json.Unmarshal(jw.value, &value); err != nil {
+			return jw.fail(i, err)
+		}
+		if err := jw.validate(value); err != nil {
+			return jw.fail(i, err)
+		}
+		jw.out = append(jw.out, jw.value...)
+		jw.value = nil
+	}
+
+	// An empty write would be taken as a flush by dst.
+	if len(jw.out) > 0 {
+		if _, err := jw.w.Write(jw.out); err != nil {
+			jw.err = err
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// fail forwards what was accepted before the invalid value and makes err sticky.
+func (jw *jsonValidatingWriter) fail(n int, err error) (int, error) {
+	jw.err = err
+	if len(jw.out) > 0 {
+		if _, werr := jw.w.Write(jw.out); werr != nil {
+			return 0, werr
+		}
+	}
+	return n, err
+}
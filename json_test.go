@@ -0,0 +1,82 @@
+package xio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONValidatingWriter(t *testing.T) {
+	errMissingID := errors.New("missing id")
+
+	validate := func(value json.RawMessage) error {
+		var obj struct {
+			ID *int `json:"id"`
+		}
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return err
+		}
+		if obj.ID == nil {
+			return errMissingID
+		}
+		return nil
+	}
+
+	t.Run("forwards valid values", func(t *testing.T) {
+		src := "{\"id\": 1, \"name\": \"brace } in string\"}\n{\"id\": 2, \"tags\": [\"a\", \"b\\\"}\"]}\n"
+
+		var dst strings.Builder
+		_, err := Copy(context.Background(), JSONValidatingWriter(&dst, validate), strings.NewReader(src), BufferSize(5))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if dst.String() != src {
+			t.Fatalf("expected dst to be %q but got %q", src, dst.String())
+		}
+	})
+
+	t.Run("does not forward empty writes", func(t *testing.T) {
+		var dst strings.Builder
+		w := WriterFunc(func(b []byte) (int, error) {
+			if len(b) == 0 {
+				t.Errorf("expected no empty writes")
+			}
+			return dst.Write(b)
+		})
+		_, err := Copy(context.Background(), JSONValidatingWriter(w, validate), strings.NewReader(`{"id": 1} {"name": "no id"}`), BufferSize(3))
+		if err != errMissingID {
+			t.Fatalf("expected err to be %#q but got %#q", errMissingID, err)
+		}
+		if expected := `{"id": 1} `; dst.String() != expected {
+			t.Fatalf("expected dst to be %q but got %q", expected, dst.String())
+		}
+	})
+
+	t.Run("aborts on rejected value", func(t *testing.T) {
+		src := "{\"id\": 1}\n{\"name\": \"no id\"}\n{\"id\": 3}\n"
+
+		var dst strings.Builder
+		_, err := Copy(context.Background(), JSONValidatingWriter(&dst, validate), strings.NewReader(src), BufferSize(4))
+		if err != errMissingID {
+			t.Fatalf("expected err to be %#q but got %#q", errMissingID, err)
+		}
+		if expected := "{\"id\": 1}\n"; dst.String() != expected {
+			t.Fatalf("expected dst to be %q but got %q", expected, dst.String())
+		}
+	})
+
+	t.Run("aborts on malformed value", func(t *testing.T) {
+		var dst strings.Builder
+		_, err := Copy(context.Background(), JSONValidatingWriter(&dst, validate), strings.NewReader(`{"id": 1,}`))
+
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected a json syntax error but got %#q", err)
+		}
+		if dst.Len() != 0 {
+			t.Fatalf("expected nothing to be written but got %q", dst.String())
+		}
+	})
+}
@@ -0,0 +1,328 @@
+package xio
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrInvalidJSON is returned by a JSONValidatorReader, wrapped in an *OffsetError, when the stream is not well
+// formed JSON.
+var ErrInvalidJSON = errors.New("invalid JSON")
+
+// JSONValidatorReader returns a reader that passes the data of r through while validating that it is well formed
+// JSON, so that a copy of a malformed upload fails as soon as the problem is read. The stream may hold several JSON
+// values separated by whitespace, as accepted by json.Decoder, but must hold at least one. On the first syntax error,
+// Read returns the bytes preceding it along with an *OffsetError wrapping ErrInvalidJSON, whose offset is the one of
+// the offending byte, or the length of the stream if it ended prematurely.
+func JSONValidatorReader(r io.Reader) io.Reader {
+	return &jsonValidatorReader{r: r}
+}
+
+type jsonValidatorReader struct {
+	r       io.Reader
+	scanner jsonScanner
+	offset  int64
+	err     error
+}
+
+func (jr *jsonValidatorReader) Read(p []byte) (int, error) {
+	if jr.err != nil {
+		return 0, jr.err
+	}
+
+	n, err := jr.r.Read(p)
+	for i, c := range p[:n] {
+		if !jr.scanner.step(c) {
+			jr.err = &OffsetError{Err: ErrInvalidJSON, Offset: jr.offset + int64(i)}
+			jr.offset += int64(i)
+			return i, jr.err
+		}
+	}
+	jr.offset += int64(n)
+
+	if err == io.EOF && !jr.scanner.eof() {
+		jr.err = &OffsetError{Err: ErrInvalidJSON, Offset: jr.offset}
+		return n, jr.err
+	}
+	return n, err
+}
+
+type jsonState int
+
+const (
+	jsonValue           jsonState = iota // expecting a value
+	jsonValueOrArrayEnd                  // after '['
+	jsonKeyOrObjectEnd                   // after '{'
+	jsonKey                              // after ',' in an object
+	jsonColon                            // after an object key
+	jsonCommaOrEnd                       // after a value within an object or array
+	jsonEnd                              // after a top level value
+	jsonString                           // within a string
+	jsonStringEscape                     // after '\' within a string
+	jsonStringUnicode                    // within the hex digits of a \u escape
+	jsonNumberSign                       // after '-'
+	jsonNumberZero                       // after a leading '0'
+	jsonNumberInt                        // within the integer part
+	jsonNumberDot                        // after '.'
+	jsonNumberFrac                       // within the fraction
+	jsonNumberExp                        // after 'e' or 'E'
+	jsonNumberExpSign                    // after the exponent sign
+	jsonNumberExpDigits                  // within the exponent
+	jsonLiteral                          // within true, false or null
+)
+
+// jsonScanner is an incremental JSON syntax checker, fed one byte at a time.
+type jsonScanner struct {
+	state   jsonState
+	stack   []byte
+	key     bool   // whether the current string is an object key
+	hex     int    // hex digits left in a \u escape
+	literal string // remaining bytes of a literal
+	values  int    // number of top level values seen
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// step advances the scanner by one byte and reports whether the stream is still valid.
+func (s *jsonScanner) step(c byte) bool {
+	switch s.state {
+	case jsonValue, jsonEnd:
+		if isJSONSpace(c) {
+			return true
+		}
+		return s.beginValue(c)
+
+	case jsonValueOrArrayEnd:
+		if isJSONSpace(c) {
+			return true
+		}
+		if c == ']' {
+			return s.endContainer('[')
+		}
+		return s.beginValue(c)
+
+	case jsonKeyOrObjectEnd, jsonKey:
+		if isJSONSpace(c) {
+			return true
+		}
+		if c == '}' && s.state == jsonKeyOrObjectEnd {
+			return s.endContainer('{')
+		}
+		if c != '"' {
+			return false
+		}
+		s.state, s.key = jsonString, true
+		return true
+
+	case jsonColon:
+		if isJSONSpace(c) {
+			return true
+		}
+		if c != ':' {
+			return false
+		}
+		s.state = jsonValue
+		return true
+
+	case jsonCommaOrEnd:
+		if isJSONSpace(c) {
+			return true
+		}
+		top := s.stack[len(s.stack)-1]
+		switch {
+		case c == ',' && top == '{':
+			s.state = jsonKey
+		case c == ',' && top == '[':
+			s.state = jsonValue
+		case c == '}':
+			return s.endContainer('{')
+		case c == ']':
+			return s.endContainer('[')
+		default:
+			return false
+		}
+		return true
+
+	case jsonString:
+		switch {
+		case c == '"':
+			if s.key {
+				s.key = false
+				s.state = jsonColon
+				return true
+			}
+			s.endValue()
+		case c == '\\':
+			s.state = jsonStringEscape
+		case c < 0x20:
+			return false
+		}
+		return true
+
+	case jsonStringEscape:
+		switch c {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			s.state = jsonString
+		case 'u':
+			s.state, s.hex = jsonStringUnicode, 4
+		default:
+			return false
+		}
+		return true
+
+	case jsonStringUnicode:
+		if !isHex(c) {
+			return false
+		}
+		if s.hex--; s.hex == 0 {
+			s.state = jsonString
+		}
+		return true
+
+	case jsonNumberSign:
+		switch {
+		case c == '0':
+			s.state = jsonNumberZero
+		case isDigit(c):
+			s.state = jsonNumberInt
+		default:
+			return false
+		}
+		return true
+
+	case jsonNumberZero, jsonNumberInt:
+		switch {
+		case isDigit(c) && s.state == jsonNumberInt:
+			return true
+		case c == '.':
+			s.state = jsonNumberDot
+			return true
+		case c == 'e' || c == 'E':
+			s.state = jsonNumberExp
+			return true
+		}
+		s.endValue()
+		return s.step(c)
+
+	case jsonNumberDot:
+		if !isDigit(c) {
+			return false
+		}
+		s.state = jsonNumberFrac
+		return true
+
+	case jsonNumberFrac:
+		switch {
+		case isDigit(c):
+			return true
+		case c == 'e' || c == 'E':
+			s.state = jsonNumberExp
+			return true
+		}
+		s.endValue()
+		return s.step(c)
+
+	case jsonNumberExp:
+		switch {
+		case c == '+' || c == '-':
+			s.state = jsonNumberExpSign
+		case isDigit(c):
+			s.state = jsonNumberExpDigits
+		default:
+			return false
+		}
+		return true
+
+	case jsonNumberExpSign:
+		if !isDigit(c) {
+			return false
+		}
+		s.state = jsonNumberExpDigits
+		return true
+
+	case jsonNumberExpDigits:
+		if isDigit(c) {
+			return true
+		}
+		s.endValue()
+		return s.step(c)
+
+	case jsonLiteral:
+		if c != s.literal[0] {
+			return false
+		}
+		if s.literal = s.literal[1:]; s.literal == "" {
+			s.endValue()
+		}
+		return true
+	}
+	return false
+}
+
+func (s *jsonScanner) beginValue(c byte) bool {
+	switch {
+	case c == '{' || c == '[':
+		s.stack = append(s.stack, c)
+		if c == '{' {
+			s.state = jsonKeyOrObjectEnd
+		} else {
+			s.state = jsonValueOrArrayEnd
+		}
+	case c == '"':
+		s.state = jsonString
+	case c == '-':
+		s.state = jsonNumberSign
+	case c == '0':
+		s.state = jsonNumberZero
+	case isDigit(c):
+		s.state = jsonNumberInt
+	case c == 't':
+		s.state, s.literal = jsonLiteral, "rue"
+	case c == 'f':
+		s.state, s.literal = jsonLiteral, "alse"
+	case c == 'n':
+		s.state, s.literal = jsonLiteral, "ull"
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *jsonScanner) endContainer(open byte) bool {
+	if len(s.stack) == 0 || s.stack[len(s.stack)-1] != open {
+		return false
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+	s.endValue()
+	return true
+}
+
+func (s *jsonScanner) endValue() {
+	if len(s.stack) == 0 {
+		s.state = jsonEnd
+		s.values++
+		return
+	}
+	s.state = jsonCommaOrEnd
+}
+
+// eof reports whether the stream may end in the current state.
+func (s *jsonScanner) eof() bool {
+	switch s.state {
+	case jsonNumberZero, jsonNumberInt, jsonNumberFrac, jsonNumberExpDigits:
+		if len(s.stack) == 0 {
+			s.endValue()
+		}
+	}
+	return s.state == jsonEnd && s.values > 0
+}
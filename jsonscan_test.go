@@ -0,0 +1,77 @@
+package xio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestJSONValidatorReader(t *testing.T) {
+	t.Run("valid json", func(t *testing.T) {
+		for _, input := range []string{
+			`{"name": "xio", "tags": ["io", "context"], "stars": 1.5e3, "fork": false, "parent": null}`,
+			`[1, -0, 0.25, -12E+2, "esc\"aped \\ é \n", {}, [], true]`,
+			`"top level string"`,
+			`42`,
+			"{\"a\": 1}\n{\"b\": [2]}\n",
+		} {
+			var dst strings.Builder
+			if _, err := Copy(context.Background(), &dst, JSONValidatorReader(strings.NewReader(input)), BufferSize(3)); err != nil {
+				t.Fatalf("expected %q to be valid but got %v", input, err)
+			}
+			if dst.String() != input {
+				t.Fatalf("expected data to pass through unchanged but got %q", dst.String())
+			}
+		}
+	})
+
+	for _, tc := range []struct {
+		name   string
+		input  string
+		offset int64
+	}{
+		{name: "unbalanced braces", input: `{"a": [1, 2}`, offset: 11},
+		{name: "unclosed object", input: `{"a": {"b": 1}`, offset: 14},
+		{name: "extra closing brace", input: `{"a": 1}}`, offset: 8},
+		{name: "bad token", input: `{"a": tru}`, offset: 9},
+		{name: "unquoted key", input: `{a: 1}`, offset: 1},
+		{name: "trailing comma", input: `[1, 2,]`, offset: 6},
+		{name: "missing colon", input: `{"a" 1}`, offset: 5},
+		{name: "leading zero", input: `[01]`, offset: 2},
+		{name: "bad escape", input: `["\x"]`, offset: 3},
+		{name: "empty", input: ``, offset: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if json.Valid([]byte(tc.input)) {
+				t.Fatalf("test input %q is valid json", tc.input)
+			}
+
+			var dst strings.Builder
+			_, err := Copy(context.Background(), &dst, JSONValidatorReader(strings.NewReader(tc.input)), BufferSize(4))
+
+			var offsetErr *OffsetError
+			if !errors.Is(err, ErrInvalidJSON) || !errors.As(err, &offsetErr) {
+				t.Fatalf("expected an invalid json offset error but got %v", err)
+			}
+			if offsetErr.Offset != tc.offset {
+				t.Fatalf("expected error at offset %d but got %d", tc.offset, offsetErr.Offset)
+			}
+			if dst.String() != tc.input[:tc.offset] {
+				t.Fatalf("expected the data preceding the error to be copied but got %q", dst.String())
+			}
+		})
+	}
+
+	t.Run("stops reading at the error", func(t *testing.T) {
+		r := JSONValidatorReader(strings.NewReader(`[1 2]`))
+		if _, err := io.ReadAll(r); !errors.Is(err, ErrInvalidJSON) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrInvalidJSON, err)
+		}
+		if _, err := r.Read(make([]byte, 4)); !errors.Is(err, ErrInvalidJSON) {
+			t.Fatalf("expected the error to be sticky but got %v", err)
+		}
+	})
+}
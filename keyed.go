@@ -0,0 +1,17 @@
+package xio
+
+// KeyedWriter is implemented by writers that accept a dedup key along with the data, used by IdempotentWrites.
+// WriteKeyed should apply p only if no write with the same key was applied before, and report it as written either way.
+type KeyedWriter interface {
+	WriteKeyed(key string, p []byte) (int, error)
+}
+
+// keyedWriter writes to w with the key computed by key for every chunk.
+type keyedWriter struct {
+	w   KeyedWriter
+	key func(chunk []byte) string
+}
+
+func (kw *keyedWriter) Write(p []byte) (int, error) {
+	return kw.w.WriteKeyed(kw.key(p), p)
+}
@@ -0,0 +1,83 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dedupWriter applies every keyed write once, and fails the first application of each chunk after applying it.
+type dedupWriter struct {
+	bytes.Buffer
+	applied map[string]bool
+	keys    []string
+}
+
+func (dw *dedupWriter) WriteKeyed(key string, p []byte) (int, error) {
+	dw.keys = append(dw.keys, key)
+	if dw.applied[key] {
+		return len(p), nil
+	}
+	dw.applied[key] = true
+	dw.Write(p)
+	return 0, retryAfterError{after: time.Millisecond}
+}
+
+func TestIdempotentWrites(t *testing.T) {
+	keyFn := func(chunk []byte) string {
+		sum := sha256.Sum256(chunk)
+		return hex.EncodeToString(sum[:])
+	}
+
+	t.Run("retried writes are applied once", func(t *testing.T) {
+		dst := &dedupWriter{applied: map[string]bool{}}
+
+		n, err := Copy(
+			context.Background(),
+			dst,
+			strings.NewReader("abcdef"),
+			BufferSize(3),
+			IdempotentWrites(keyFn),
+			RetryAfter(extractRetryAfter),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 6 || dst.String() != "abcdef" {
+			t.Fatalf("expected data to be applied once but got %d bytes: %q", n, dst.String())
+		}
+
+		expected := []string{keyFn([]byte("abc")), keyFn([]byte("abc")), keyFn([]byte("def")), keyFn([]byte("def"))}
+		if strings.Join(dst.keys, ",") != strings.Join(expected, ",") {
+			t.Fatalf("expected each chunk to be written twice with the same key but got %v", dst.keys)
+		}
+	})
+
+	t.Run("without the option dst is written to directly", func(t *testing.T) {
+		dst := &dedupWriter{applied: map[string]bool{}}
+
+		_, err := Copy(context.Background(), dst, strings.NewReader("abcdef"))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if len(dst.keys) != 0 {
+			t.Fatalf("expected no keyed writes but got %v", dst.keys)
+		}
+	})
+
+	t.Run("failures are returned", func(t *testing.T) {
+		dst := &dedupWriter{applied: map[string]bool{}}
+
+		_, err := Copy(context.Background(), dst, strings.NewReader("abcdef"), IdempotentWrites(keyFn))
+
+		var retryErr retryAfterError
+		if !errors.As(err, &retryErr) {
+			t.Fatalf("expected the write error to be returned but got %v", err)
+		}
+	})
+}
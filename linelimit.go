@@ -0,0 +1,63 @@
+package xio
+
+import (
+	"bytes"
+	"io"
+)
+
+// LineLimitReader returns a reader that reports io.EOF once maxLines newline terminated lines have been read from r,
+// for bounded log tailing. A last line without a newline is returned as is if r ends first. Data past the last line
+// is left unread where possible: if r implements io.ByteReader, such as a *bufio.Reader, it is read one byte at a time
+// so that nothing is read past the limit, and otherwise, if r implements io.Seeker, it is seeked back over the bytes
+// read past the limit. Other readers may have consumed up to one buffer of data past the limit.
+func LineLimitReader(r io.Reader, maxLines int) io.Reader {
+	return &lineLimitReader{r: r, remaining: maxLines}
+}
+
+type lineLimitReader struct {
+	r         io.Reader
+	remaining int
+}
+
+func (lr *lineLimitReader) Read(p []byte) (int, error) {
+	if lr.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if br, ok := lr.r.(io.ByteReader); ok {
+		var n int
+		for n < len(p) && lr.remaining > 0 {
+			b, err := br.ReadByte()
+			if err != nil {
+				return n, err
+			}
+			p[n] = b
+			n++
+			if b == '\n' {
+				lr.remaining--
+			}
+		}
+		return n, nil
+	}
+
+	n, err := lr.r.Read(p)
+	for i := 0; i < n; {
+		nl := bytes.IndexByte(p[i:n], '\n')
+		if nl < 0 {
+			break
+		}
+		i += nl + 1
+		if lr.remaining--; lr.remaining == 0 {
+			if seeker, ok := lr.r.(io.Seeker); ok && i < n {
+				if _, err := seeker.Seek(int64(i-n), io.SeekCurrent); err != nil {
+					return i, err
+				}
+			}
+			return i, nil
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,60 @@
+package xio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineLimitReader(t *testing.T) {
+	const lines = "one\ntwo\nthree\nfour\n"
+
+	for _, tc := range []struct {
+		name     string
+		src      string
+		maxLines int
+		expected string
+	}{
+		{name: "more lines than the limit", src: lines, maxLines: 2, expected: "one\ntwo\n"},
+		{name: "fewer lines than the limit", src: lines, maxLines: 10, expected: lines},
+		{name: "final line without newline", src: "one\ntwo", maxLines: 3, expected: "one\ntwo"},
+		{name: "zero lines", src: lines, maxLines: 0, expected: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, src := range []struct {
+				kind string
+				r    io.Reader
+			}{
+				{kind: "plain", r: ReaderFunc(strings.NewReader(tc.src).Read)},
+				{kind: "byte reader", r: bufio.NewReader(strings.NewReader(tc.src))},
+				{kind: "seeker", r: struct{ io.ReadSeeker }{strings.NewReader(tc.src)}},
+			} {
+				var dst bytes.Buffer
+				if _, err := Copy(context.Background(), &dst, LineLimitReader(src.r, tc.maxLines), BufferSize(6)); err != nil {
+					t.Fatalf("%s: expected err to be nil but got %#q", src.kind, err)
+				}
+				if dst.String() != tc.expected {
+					t.Fatalf("%s: expected %q but got %q", src.kind, tc.expected, dst.String())
+				}
+			}
+		})
+	}
+
+	t.Run("rest is left unread", func(t *testing.T) {
+		for _, r := range []io.Reader{bufio.NewReader(strings.NewReader(lines)), struct{ io.ReadSeeker }{strings.NewReader(lines)}} {
+			if _, err := io.ReadAll(LineLimitReader(r, 2)); err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			rest, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if string(rest) != "three\nfour\n" {
+				t.Fatalf("expected the rest to be left unread but got %q", rest)
+			}
+		}
+	})
+}
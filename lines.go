@@ -0,0 +1,134 @@
+package xio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// lineWriter calls fn with every complete line written to it, terminator included, handling lines split across
+// writes. A final line without terminator is passed to fn when the writer is finished.
+type lineWriter struct {
+	w    io.Writer
+	fn   func(w io.Writer, line []byte) error
+	line []byte
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	var consumed int
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			lw.line = append(lw.line, p...)
+			return consumed + len(p), nil
+		}
+
+		line := p[:i+1]
+		if len(lw.line) > 0 {
+			lw.line = append(lw.line, line...)
+			line = lw.line
+		}
+		if err := lw.fn(lw.w, line); err != nil {
+			return consumed, err
+		}
+
+		lw.line = lw.line[:0]
+		consumed += i + 1
+		p = p[i+1:]
+	}
+	return consumed, nil
+}
+
+func (lw *lineWriter) finish() error {
+	if len(lw.line) == 0 {
+		return nil
+	}
+	line := lw.line
+	lw.line = nil
+	return lw.fn(lw.w, line)
+}
+
+// trailingNewlineWriter remembers the last byte written to w so it can terminate the last line when finished.
+type trailingNewlineWriter struct {
+	w    io.Writer
+	last byte
+	any  bool
+}
+
+func (tw *trailingNewlineWriter) Write(p []byte) (int, error) {
+	n, err := tw.w.Write(p)
+	if n > 0 {
+		tw.last = p[n-1]
+		tw.any = true
+	}
+	return n, err
+}
+
+func (tw *trailingNewlineWriter) finish() error {
+	if !tw.any || tw.last == '\n' {
+		return nil
+	}
+	_, err := tw.w.Write([]byte{'\n'})
+	return err
+}
+
+// trimLineTerminator returns line without its trailing "\n" or "\r\n".
+func trimLineTerminator(line []byte) []byte {
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		return line
+	}
+	return bytes.TrimSuffix(line[:len(line)-1], []byte("\r"))
+}
+
+// uniqWriter writes the lines given to it by a lineWriter, dropping the ones identical to the previous line. When
+// counting, a line is only written once a different one comes along, prefixed with its number of occurrences.
+type uniqWriter struct {
+	*lineWriter
+	count   bool
+	prev    []byte
+	n       int
+	holding bool
+}
+
+func newUniqWriter(w io.Writer, count bool) *uniqWriter {
+	uw := &uniqWriter{count: count}
+	uw.lineWriter = &lineWriter{w: w, fn: uw.line}
+	return uw
+}
+
+func (uw *uniqWriter) line(w io.Writer, line []byte) error {
+	if uw.holding && bytes.Equal(trimLineTerminator(line), trimLineTerminator(uw.prev)) {
+		uw.n++
+		return nil
+	}
+	if err := uw.flush(); err != nil {
+		return err
+	}
+
+	uw.prev = append(uw.prev[:0], line...)
+	uw.n = 1
+	uw.holding = true
+	if uw.count {
+		return nil
+	}
+	_, err := w.Write(line)
+	return err
+}
+
+// flush writes out the held line along with its count.
+func (uw *uniqWriter) flush() error {
+	if !uw.count || !uw.holding {
+		return nil
+	}
+	_, err := fmt.Fprintf(uw.w, "%d %s", uw.n, uw.prev)
+	return err
+}
+
+func (uw *uniqWriter) finish() error {
+	if err := uw.lineWriter.finish(); err != nil {
+		return err
+	}
+	err := uw.flush()
+	uw.holding = false
+	return err
+}
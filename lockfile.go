@@ -0,0 +1,87 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrLockTimeout is returned by CopyToLockedFile when the lock could not be acquired within the LockTimeout.
+var ErrLockTimeout = errors.New("timed out waiting for file lock")
+
+// ErrLockUnsupported is returned by CopyToLockedFile on platforms without advisory file locks.
+var ErrLockUnsupported = errors.New("file locking not supported on this platform")
+
+// lockPoll is how often CopyToLockedFile tries to acquire a lock held by someone else.
+const lockPoll = 10 * time.Millisecond
+
+// CopyToLockedFile copies src to the file at path, creating it if needed, while holding an exclusive advisory lock on
+// it, so that cooperating writers never interleave. The lock is acquired before the file is truncated, waiting for it
+// until the context is canceled or the duration given by LockTimeout elapses, in which case ErrLockTimeout is returned.
+// The file is synced to disk after the copy, and the lock is released whatever the outcome. Since the lock must not
+// be released while the copy goroutine still writes to the file, CopyToLockedFile always waits for the last operation,
+// regardless of WaitForLastOp.
+//
+// Locks are acquired with flock on unix platforms, and only exclude other flock users: processes that don't lock the
+// file can still write to it. Other platforms return ErrLockUnsupported.
+func CopyToLockedFile(ctx context.Context, path string, src io.Reader, opts ...CopyOption) (n int64, err error) {
+	var options copyoptions
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o666)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if err := lockFile(ctx, f, options.lockTimeout); err != nil {
+		return 0, err
+	}
+	defer func() {
+		if unlockErr := unlockFile(f); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+	if n, err = Copy(ctx, f, src, append(opts, WaitForLastOp(true))...); err != nil {
+		return n, err
+	}
+	return n, f.Sync()
+}
+
+// lockFile acquires an exclusive lock on f, retrying until it succeeds, the context is canceled or timeout elapses.
+// A timeout of zero or less waits indefinitely.
+func lockFile(ctx context.Context, f *os.File, timeout time.Duration) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		locked, err := tryLockFile(f)
+		if locked || err != nil {
+			return err
+		}
+
+		wait := lockPoll
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return ErrLockTimeout
+			}
+			if remaining < wait {
+				wait = remaining
+			}
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
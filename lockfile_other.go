@@ -0,0 +1,13 @@
+//go:build !unix
+
+package xio
+
+import "os"
+
+func tryLockFile(f *os.File) (bool, error) {
+	return false, ErrLockUnsupported
+}
+
+func unlockFile(f *os.File) error {
+	return ErrLockUnsupported
+}
@@ -0,0 +1,149 @@
+//go:build unix
+
+package xio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCopyToLockedFile(t *testing.T) {
+	tryLock := func(t *testing.T, path string) (*os.File, error) {
+		t.Helper()
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return f, nil
+	}
+
+	t.Run("lock held during copy and released after", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out")
+		if err := os.WriteFile(path, []byte("previous contents that are longer"), 0o644); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		src := ReaderFunc(strings.NewReader("hello world").Read)
+		var checked bool
+		n, err := CopyToLockedFile(context.Background(), path, ReaderFunc(func(b []byte) (int, error) {
+			if !checked {
+				checked = true
+				if f, err := tryLock(t, path); !errors.Is(err, unix.EWOULDBLOCK) {
+					if f != nil {
+						f.Close()
+					}
+					t.Errorf("expected lock to be held during copy but got %#q", err)
+				}
+			}
+			return src.Read(b)
+		}))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 || !checked {
+			t.Fatalf("expected 11 bytes to be copied but got %d", n)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(data) != "hello world" {
+			t.Fatalf("expected file to be replaced but got %q", data)
+		}
+
+		f, err := tryLock(t, path)
+		if err != nil {
+			t.Fatalf("expected lock to be released after copy but got %#q", err)
+		}
+		f.Close()
+	})
+
+	t.Run("released on error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out")
+		readErr := errors.New("read failed")
+
+		_, err := CopyToLockedFile(context.Background(), path, ReaderFunc(func(b []byte) (int, error) {
+			return 0, readErr
+		}))
+		if !errors.Is(err, readErr) {
+			t.Fatalf("expected err to be %#q but got %#q", readErr, err)
+		}
+
+		f, err := tryLock(t, path)
+		if err != nil {
+			t.Fatalf("expected lock to be released after failed copy but got %#q", err)
+		}
+		f.Close()
+	})
+
+	t.Run("timeout while locked elsewhere", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out")
+		if err := os.WriteFile(path, []byte("keep"), 0o644); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		holder, err := tryLock(t, path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		defer holder.Close()
+
+		start := time.Now()
+		_, err = CopyToLockedFile(context.Background(), path, strings.NewReader("overwrite"), LockTimeout(30*time.Millisecond))
+		if !errors.Is(err, ErrLockTimeout) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrLockTimeout, err)
+		}
+		if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+			t.Fatalf("expected to wait for the timeout but returned after %v", elapsed)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "keep" {
+			t.Fatalf("expected file to be untouched but got %q", data)
+		}
+	})
+
+	t.Run("canceled while locked elsewhere", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out")
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		holder, err := tryLock(t, path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		defer holder.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if _, err := CopyToLockedFile(ctx, path, strings.NewReader("data")); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("acquires once released", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out")
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		holder, err := tryLock(t, path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		time.AfterFunc(20*time.Millisecond, func() { holder.Close() })
+
+		if _, err := CopyToLockedFile(context.Background(), path, strings.NewReader("data"), LockTimeout(time.Second)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+type copyIDKey struct{}
+
+// WithCopyID returns a context carrying id, identifying the copies made with it in the records of WithLogger.
+func WithCopyID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, copyIDKey{}, id)
+}
+
+// CopyIDFromContext returns the copy ID set by WithCopyID, if any.
+func CopyIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(copyIDKey{}).(string)
+	return id, ok
+}
+
+// logCopy logs the start of a copy and returns the func logging its outcome.
+func logCopy(ctx context.Context, l *slog.Logger, start time.Time) func(n *int64, err *error) {
+	var attrs []slog.Attr
+	if id, ok := CopyIDFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("copy_id", id))
+	}
+	l.LogAttrs(ctx, slog.LevelDebug, "copy started", attrs...)
+
+	return func(n *int64, err *error) {
+		attrs := append(attrs, slog.Int64("bytes", *n), slog.Duration("duration", time.Since(start)))
+		switch {
+		case *err == nil:
+			l.LogAttrs(ctx, slog.LevelInfo, "copy completed", attrs...)
+		case errors.Is(*err, context.Canceled) || errors.Is(*err, context.DeadlineExceeded):
+			l.LogAttrs(ctx, slog.LevelWarn, "copy canceled", append(attrs, slog.Any("error", *err))...)
+		default:
+			l.LogAttrs(ctx, slog.LevelError, "copy failed", append(attrs, slog.Any("error", *err))...)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordHandler captures the records logged through it.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler      { return h }
+
+func recordAttrs(r slog.Record) map[string]slog.Value {
+	attrs := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value
+		return true
+	})
+	return attrs
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Run("completed", func(t *testing.T) {
+		h := &recordHandler{}
+		ctx := WithCopyID(context.Background(), "upload-42")
+
+		if _, err := Copy(ctx, io.Discard, strings.NewReader("hello world"), WithLogger(slog.New(h))); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		if len(h.records) != 2 {
+			t.Fatalf("expected 2 records but got %d", len(h.records))
+		}
+
+		started, completed := h.records[0], h.records[1]
+		if started.Level != slog.LevelDebug || started.Message != "copy started" {
+			t.Fatalf("expected a debug start record but got %v %q", started.Level, started.Message)
+		}
+		if id := recordAttrs(started)["copy_id"]; id.String() != "upload-42" {
+			t.Fatalf("expected copy id to be logged but got %v", id)
+		}
+
+		if completed.Level != slog.LevelInfo || completed.Message != "copy completed" {
+			t.Fatalf("expected an info completion record but got %v %q", completed.Level, completed.Message)
+		}
+		attrs := recordAttrs(completed)
+		if attrs["copy_id"].String() != "upload-42" {
+			t.Fatalf("expected copy id to be logged but got %v", attrs["copy_id"])
+		}
+		if attrs["bytes"].Int64() != 11 {
+			t.Fatalf("expected bytes to be 11 but got %v", attrs["bytes"])
+		}
+		if attrs["duration"].Kind() != slog.KindDuration {
+			t.Fatalf("expected duration to be logged but got %v", attrs["duration"])
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		h := &recordHandler{}
+		readErr := errors.New("read failed")
+
+		_, err := Copy(context.Background(), io.Discard, ReaderFunc(func(b []byte) (int, error) {
+			return copy(b, "abc"), readErr
+		}), WithLogger(slog.New(h)))
+		if err != readErr {
+			t.Fatalf("expected err to be %#q but got %#q", readErr, err)
+		}
+
+		failed := h.records[len(h.records)-1]
+		if failed.Level != slog.LevelError || failed.Message != "copy failed" {
+			t.Fatalf("expected an error record but got %v %q", failed.Level, failed.Message)
+		}
+		attrs := recordAttrs(failed)
+		if attrs["error"].Any() != readErr || attrs["bytes"].Int64() != 3 {
+			t.Fatalf("expected error and bytes to be logged but got %v", attrs)
+		}
+		if _, ok := attrs["copy_id"]; ok {
+			t.Fatalf("expected no copy id without one on the context")
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		h := &recordHandler{}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		Copy(ctx, io.Discard, ReaderFunc(func(b []byte) (int, error) {
+			time.Sleep(5 * time.Millisecond)
+			return copy(b, "x"), nil
+		}), WithLogger(slog.New(h)))
+
+		canceled := h.records[len(h.records)-1]
+		if canceled.Level != slog.LevelWarn || canceled.Message != "copy canceled" {
+			t.Fatalf("expected a warn record but got %v %q", canceled.Level, canceled.Message)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package xio
+
+// fitMemory lowers the read ahead depth, the number of coalesced chunks and the buffer size, in that order, until the
+// buffers of the copy fit in maxMemory.
+func (options *copyoptions) fitMemory() {
+	size := options.bufferSize
+	if size < 1 {
+		return
+	}
+	budget := options.maxMemory / size
+
+	if options.readAhead > 1 {
+		options.readAhead = clampBuffers(budget-options.coalesceChunks, options.readAhead)
+	}
+
+	readBuffers := 1
+	if options.readAhead > 1 {
+		readBuffers = options.readAhead
+	}
+
+	if options.coalesceChunks > 1 {
+		options.coalesceChunks = clampBuffers(budget-readBuffers, options.coalesceChunks)
+	}
+
+	if buffers := readBuffers + options.coalesceChunks; size*buffers > options.maxMemory {
+		options.bufferSize = clampBuffers(options.maxMemory/buffers, size)
+	}
+}
+
+// clampBuffers bounds n between 1 and max.
+func clampBuffers(n, max int) int {
+	if n < 1 {
+		return 1
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
@@ -0,0 +1,102 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMaxMemory(t *testing.T) {
+	type config struct {
+		bufferSize, readAhead, coalesceChunks int
+	}
+
+	for _, tc := range []struct {
+		name     string
+		opts     []CopyOption
+		expected config
+	}{
+		{
+			name:     "within the cap",
+			opts:     []CopyOption{BufferSize(1024), ReadAhead(4), CoalesceWrites(4), MaxMemory(8 * 1024)},
+			expected: config{bufferSize: 1024, readAhead: 4, coalesceChunks: 4},
+		},
+		{
+			name:     "read ahead is lowered first",
+			opts:     []CopyOption{BufferSize(1024), ReadAhead(8), CoalesceWrites(4), MaxMemory(10 * 1024)},
+			expected: config{bufferSize: 1024, readAhead: 6, coalesceChunks: 4},
+		},
+		{
+			name:     "then coalesced chunks",
+			opts:     []CopyOption{BufferSize(1024), ReadAhead(8), CoalesceWrites(4), MaxMemory(3 * 1024)},
+			expected: config{bufferSize: 1024, readAhead: 1, coalesceChunks: 2},
+		},
+		{
+			name:     "then the buffer size",
+			opts:     []CopyOption{BufferSize(1024), ReadAhead(8), CoalesceWrites(4), MaxMemory(1000)},
+			expected: config{bufferSize: 500, readAhead: 1, coalesceChunks: 1},
+		},
+		{
+			name:     "default buffer size",
+			opts:     []CopyOption{MaxMemory(1024)},
+			expected: config{bufferSize: 1024},
+		},
+		{
+			name:     "given buffer",
+			opts:     []CopyOption{Buffer(make([]byte, 4096)), ReadAhead(2), MaxMemory(2048)},
+			expected: config{bufferSize: 2048, readAhead: 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			options := copyoptions{bufferSize: defaultBufferSize}
+			for _, apply := range tc.opts {
+				apply(&options)
+			}
+			if options.buffer != nil {
+				options.bufferSize = len(options.buffer)
+			}
+			options.fitMemory()
+
+			actual := config{bufferSize: options.bufferSize, readAhead: options.readAhead, coalesceChunks: options.coalesceChunks}
+			if actual != tc.expected {
+				t.Fatalf("expected configuration to be %+v but got %+v", tc.expected, actual)
+			}
+
+			memory := options.bufferSize * (options.readAhead + options.coalesceChunks)
+			if options.readAhead == 0 {
+				memory += options.bufferSize
+			}
+			if memory > options.maxMemory {
+				t.Fatalf("expected memory to be at most %d but got %d", options.maxMemory, memory)
+			}
+		})
+	}
+
+	t.Run("copy uses the capped buffer", func(t *testing.T) {
+		var sizes []int
+		var dst bytes.Buffer
+
+		n, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				sizes = append(sizes, len(b))
+				return dst.Write(b)
+			}),
+			strings.NewReader(strings.Repeat("x", 100)),
+			Buffer(make([]byte, 64)),
+			MaxMemory(40),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 100 || dst.Len() != 100 {
+			t.Fatalf("expected all data to be copied but got %d bytes", n)
+		}
+		for _, size := range sizes {
+			if size > 40 {
+				t.Fatalf("expected chunks of at most 40 bytes but got %v", sizes)
+			}
+		}
+	})
+}
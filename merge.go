@@ -0,0 +1,84 @@
+package xio
+
+import (
+	"bufio"
+	"io"
+)
+
+// MergeSortedReaders returns a reader performing a streaming merge of the lines of a and b, each of which must
+// already be sorted according to less, producing a single sorted stream as in an external merge sort. less is given
+// lines without their terminator. On equal lines, the line from a comes first. Once either reader is exhausted the
+// remaining lines of the other are passed through. A final line without a newline is terminated with one if other
+// lines follow it in the merged output.
+func MergeSortedReaders(a, b io.Reader, less func(x, y []byte) bool) io.Reader {
+	return &mergeReader{
+		sides: [2]mergeSide{{r: bufio.NewReader(a)}, {r: bufio.NewReader(b)}},
+		less:  less,
+	}
+}
+
+type mergeSide struct {
+	r    *bufio.Reader
+	line []byte
+	err  error
+}
+
+// next ensures line holds the next line of the side unless it is exhausted.
+func (s *mergeSide) next() {
+	if s.line != nil || s.err != nil {
+		return
+	}
+	line, err := s.r.ReadBytes('\n')
+	if len(line) > 0 {
+		s.line = line
+	}
+	s.err = err
+}
+
+type mergeReader struct {
+	sides          [2]mergeSide
+	less           func(x, y []byte) bool
+	pending        []byte
+	missingNewline bool
+}
+
+func (mr *mergeReader) Read(p []byte) (int, error) {
+	if len(mr.pending) == 0 {
+		a, b := &mr.sides[0], &mr.sides[1]
+		a.next()
+		b.next()
+		for _, s := range []*mergeSide{a, b} {
+			if s.line == nil && s.err != io.EOF {
+				return 0, s.err
+			}
+		}
+
+		var side *mergeSide
+		switch {
+		case a.line != nil && b.line != nil:
+			side = a
+			if mr.less(trimLineTerminator(b.line), trimLineTerminator(a.line)) {
+				side = b
+			}
+		case a.line != nil:
+			side = a
+		case b.line != nil:
+			side = b
+		}
+
+		if side == nil {
+			return 0, io.EOF
+		}
+
+		if mr.missingNewline {
+			mr.pending = append(mr.pending, '\n')
+		}
+		mr.pending = append(mr.pending, side.line...)
+		mr.missingNewline = side.line[len(side.line)-1] != '\n'
+		side.line = nil
+	}
+
+	n := copy(p, mr.pending)
+	mr.pending = mr.pending[n:]
+	return n, nil
+}
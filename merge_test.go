@@ -0,0 +1,55 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMergeSortedReaders(t *testing.T) {
+	less := func(x, y []byte) bool { return bytes.Compare(x, y) < 0 }
+
+	for _, tc := range []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{
+			name:     "interleaved",
+			a:        "apple\ncherry\nfig\n",
+			b:        "banana\ncherry\ndate\n",
+			expected: "apple\nbanana\ncherry\ncherry\ndate\nfig\n",
+		},
+		{
+			name:     "uneven lengths",
+			a:        "b\n",
+			b:        "a\nc\nd\ne\n",
+			expected: "a\nb\nc\nd\ne\n",
+		},
+		{
+			name:     "final lines without newline",
+			a:        "a\nd",
+			b:        "b\nc",
+			expected: "a\nb\nc\nd",
+		},
+		{
+			name:     "empty side",
+			a:        "",
+			b:        "x\ny\n",
+			expected: "x\ny\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var dst strings.Builder
+
+			r := MergeSortedReaders(strings.NewReader(tc.a), strings.NewReader(tc.b), less)
+			if _, err := Copy(context.Background(), &dst, r, BufferSize(3)); err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if dst.String() != tc.expected {
+				t.Fatalf("expected %q but got %q", tc.expected, dst.String())
+			}
+		})
+	}
+}
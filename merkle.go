@@ -0,0 +1,92 @@
+package xio
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// CopyMerkle copies src into dst like Copy while hashing every leafSize block of the data written to dst with a hash
+// from newHash, the last block possibly being shorter. It returns the hashes of the blocks, the leaves of a Merkle
+// tree whose root can be computed with MerkleRoot. Leaves are only returned when the copy succeeds. An error is
+// returned without copying anything if leafSize is not positive.
+func CopyMerkle(ctx context.Context, dst io.Writer, src io.Reader, leafSize int, newHash func() hash.Hash, opts ...CopyOption) (int64, [][]byte, error) {
+	if leafSize <= 0 {
+		return 0, nil, fmt.Errorf("xio: CopyMerkle: invalid leaf size %d", leafSize)
+	}
+	leaves := &merkleLeaves{size: leafSize, h: newHash()}
+
+	n, err := Copy(ctx, dst, src, append(opts, withTap(func() tap {
+		return tap{write: leaves.write, done: leaves.done}
+	}))...)
+	if err != nil {
+		return n, nil, err
+	}
+	return n, leaves.hashes, nil
+}
+
+// MerkleRoot computes the root of the binary Merkle tree over leaves. Each level is built by hashing the
+// concatenation of every pair of adjacent nodes, a node without a sibling being promoted to the next level as is,
+// until a single node remains. The root of a single leaf is the leaf itself, and the root of no leaves is the hash of
+// no data.
+func MerkleRoot(leaves [][]byte, newHash func() hash.Hash) []byte {
+	h := newHash()
+	if len(leaves) == 0 {
+		return h.Sum(nil)
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			h.Reset()
+			h.Write(level[i])
+			h.Write(level[i+1])
+			next = append(next, h.Sum(nil))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+// merkleLeaves hashes every size bytes written to it.
+type merkleLeaves struct {
+	size    int
+	h       hash.Hash
+	pending int
+	hashes  [][]byte
+}
+
+func (ml *merkleLeaves) write(p []byte) {
+	for len(p) > 0 {
+		chunk := p
+		if remaining := ml.size - ml.pending; len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		ml.h.Write(chunk)
+		ml.pending += len(chunk)
+		p = p[len(chunk):]
+
+		if ml.pending == ml.size {
+			ml.leaf()
+		}
+	}
+}
+
+func (ml *merkleLeaves) done() error {
+	if ml.pending > 0 {
+		ml.leaf()
+	}
+	return nil
+}
+
+func (ml *merkleLeaves) leaf() {
+	ml.hashes = append(ml.hashes, ml.h.Sum(nil))
+	ml.h.Reset()
+	ml.pending = 0
+}
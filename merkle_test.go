@@ -0,0 +1,148 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"hash"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCopyMerkle(t *testing.T) {
+	sum := func(parts ...string) []byte {
+		h := sha256.New()
+		for _, part := range parts {
+			h.Write([]byte(part))
+		}
+		return h.Sum(nil)
+	}
+
+	t.Run("leaves", func(t *testing.T) {
+		var dst bytes.Buffer
+
+		n, leaves, err := CopyMerkle(context.Background(), &dst, strings.NewReader("aaaabbbbcc"), 4, sha256.New, BufferSize(3))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 10 || dst.String() != "aaaabbbbcc" {
+			t.Fatalf("expected data to be copied but got %d bytes: %q", n, dst.String())
+		}
+
+		expected := [][]byte{sum("aaaa"), sum("bbbb"), sum("cc")}
+		if !reflect.DeepEqual(leaves, expected) {
+			t.Fatalf("expected %d leaves hashing each block but got %d", len(expected), len(leaves))
+		}
+	})
+
+	t.Run("concatenated leaves hash to the root", func(t *testing.T) {
+		_, leaves, err := CopyMerkle(context.Background(), &bytes.Buffer{}, strings.NewReader("aaaabbbb"), 4, sha256.New)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if len(leaves) != 2 {
+			t.Fatalf("expected 2 leaves but got %d", len(leaves))
+		}
+
+		expected := sum(string(leaves[0]) + string(leaves[1]))
+		if root := MerkleRoot(leaves, sha256.New); !bytes.Equal(root, expected) {
+			t.Fatalf("expected root to be %x but got %x", expected, root)
+		}
+	})
+
+	t.Run("invalid leaf size", func(t *testing.T) {
+		for _, size := range []int{0, -1} {
+			var dst bytes.Buffer
+			n, leaves, err := CopyMerkle(context.Background(), &dst, strings.NewReader("aaaa"), size, sha256.New)
+			if err == nil {
+				t.Fatalf("expected an error for a leaf size of %d", size)
+			}
+			if n != 0 || leaves != nil || dst.Len() != 0 {
+				t.Fatalf("expected nothing to be copied but got %d bytes and %d leaves", n, len(leaves))
+			}
+		}
+	})
+}
+
+func TestMerkleRoot(t *testing.T) {
+	leaf := func(s string) []byte {
+		h := sha256.Sum256([]byte(s))
+		return h[:]
+	}
+	node := func(left, right []byte) []byte {
+		h := sha256.Sum256(append(append([]byte{}, left...), right...))
+		return h[:]
+	}
+
+	a, b, c := leaf("a"), leaf("b"), leaf("c")
+	empty := sha256.Sum256(nil)
+
+	for _, tc := range []struct {
+		name     string
+		leaves   [][]byte
+		expected []byte
+	}{
+		{name: "no leaves", leaves: nil, expected: empty[:]},
+		{name: "single leaf", leaves: [][]byte{a}, expected: a},
+		{name: "two leaves", leaves: [][]byte{a, b}, expected: node(a, b)},
+		{name: "odd leaf is promoted", leaves: [][]byte{a, b, c}, expected: node(node(a, b), c)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if root := MerkleRoot(tc.leaves, func() hash.Hash { return sha256.New() }); !bytes.Equal(root, tc.expected) {
+				t.Fatalf("expected root to be %x but got %x", tc.expected, root)
+			}
+		})
+	}
+}
+
+func TestMerkleTree(t *testing.T) {
+	data := strings.Repeat("0123456789", 10)
+
+	// independent computation over blocks of 32 bytes: 4 leaves, the last one 4 bytes long.
+	var leaves [][]byte
+	for i := 0; i < len(data); i += 32 {
+		end := i + 32
+		if end > len(data) {
+			end = len(data)
+		}
+		leaf := sha256.Sum256([]byte(data[i:end]))
+		leaves = append(leaves, leaf[:])
+	}
+	pair := func(left, right []byte) []byte {
+		h := sha256.Sum256(append(append([]byte{}, left...), right...))
+		return h[:]
+	}
+	expected := pair(pair(leaves[0], leaves[1]), pair(leaves[2], leaves[3]))
+
+	var root []byte
+	_, err := Copy(
+		context.Background(),
+		&bytes.Buffer{},
+		strings.NewReader(data),
+		BufferSize(7),
+		MerkleTree(32, func(r []byte) { root = r }),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+	if !bytes.Equal(root, expected) {
+		t.Fatalf("expected root to be %x but got %x", expected, root)
+	}
+}
+
+func TestMerkleTreeInvalidBlockSize(t *testing.T) {
+	var dst bytes.Buffer
+	n, err := Copy(
+		context.Background(),
+		&dst,
+		strings.NewReader("aaaa"),
+		MerkleTree(0, func(root []byte) { t.Error("expected fn not to be called") }),
+	)
+	if err == nil {
+		t.Fatal("expected an error for a block size of 0")
+	}
+	if n != 0 || dst.Len() != 0 {
+		t.Fatalf("expected nothing to be copied but got %d bytes", n)
+	}
+}
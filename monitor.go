@@ -0,0 +1,55 @@
+package xio
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// MonitoredRW instruments an io.ReadWriter used in both directions, such as a net.Conn handed to two Copy calls.
+// Its counters are safe to read while copies are in progress.
+type MonitoredRW struct {
+	rw           io.ReadWriter
+	read         atomic.Int64
+	written      atomic.Int64
+	lastActivity atomic.Int64
+}
+
+// Monitor returns a MonitoredRW wrapping rw.
+func Monitor(rw io.ReadWriter) *MonitoredRW {
+	return &MonitoredRW{rw: rw}
+}
+
+func (m *MonitoredRW) Read(p []byte) (int, error) {
+	n, err := m.rw.Read(p)
+	if n > 0 {
+		m.read.Add(int64(n))
+		m.lastActivity.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+func (m *MonitoredRW) Write(p []byte) (int, error) {
+	n, err := m.rw.Write(p)
+	if n > 0 {
+		m.written.Add(int64(n))
+		m.lastActivity.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// BytesRead returns the number of bytes read so far.
+func (m *MonitoredRW) BytesRead() int64 { return m.read.Load() }
+
+// BytesWritten returns the number of bytes written so far.
+func (m *MonitoredRW) BytesWritten() int64 { return m.written.Load() }
+
+// LastActivity returns the time at which bytes were last read or written, or the zero time if there was no
+// activity yet.
+func (m *MonitoredRW) LastActivity() time.Time {
+	nanos := m.lastActivity.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,43 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonitor(t *testing.T) {
+	conn := Monitor(&bytes.Buffer{})
+
+	if !conn.LastActivity().IsZero() {
+		t.Fatalf("expected no activity yet but got %v", conn.LastActivity())
+	}
+
+	before := time.Now()
+	if _, err := Copy(context.Background(), conn, strings.NewReader("hello world")); err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+
+	if conn.BytesWritten() != 11 || conn.BytesRead() != 0 {
+		t.Fatalf("expected 11 bytes written and none read but got %d and %d", conn.BytesWritten(), conn.BytesRead())
+	}
+	afterWrite := conn.LastActivity()
+	if afterWrite.Before(before) {
+		t.Fatalf("expected last activity %v to be after %v", afterWrite, before)
+	}
+
+	time.Sleep(time.Millisecond)
+
+	if _, err := Copy(context.Background(), io.Discard, io.LimitReader(conn, 5)); err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+	if conn.BytesWritten() != 11 || conn.BytesRead() != 5 {
+		t.Fatalf("expected 11 bytes written and 5 read but got %d and %d", conn.BytesWritten(), conn.BytesRead())
+	}
+	if !conn.LastActivity().After(afterWrite) {
+		t.Fatalf("expected last activity to advance past %v but got %v", afterWrite, conn.LastActivity())
+	}
+}
@@ -0,0 +1,50 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// CopyMultipart parses src as a multipart body delimited by boundary and, for every part, calls part with its headers
+// to pick a destination, and copies the body of the part into it like Copy, with the given options. A nil writer
+// skips the part. It returns the total number of body bytes written. An error returned by part aborts the copy.
+// Parsing is cancelable as well as the copies: src is read on a separate goroutine, which, like with
+// WaitForLastOp(false), may outlive the call until its pending read returns.
+func CopyMultipart(ctx context.Context, src io.Reader, boundary string, part func(headers textproto.MIMEHeader) (io.Writer, error), opts ...CopyOption) (int64, error) {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	go func() {
+		_, err := Copy(ctx, pw, src, WaitForLastOp(false))
+		pw.CloseWithError(err)
+	}()
+
+	mr := multipart.NewReader(pr, boundary)
+
+	var total int64
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+
+		dst, err := part(p.Header)
+		if err != nil {
+			return total, err
+		}
+		if dst == nil {
+			continue
+		}
+
+		n, err := Copy(ctx, dst, p, opts...)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+}
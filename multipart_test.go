@@ -0,0 +1,92 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyMultipart(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ name, content string }{
+		{name: "first", content: "hello world"},
+		{name: "second", content: strings.Repeat("multipart ", 100)},
+	} {
+		w, err := mw.CreateFormField(part.name)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		io.WriteString(w, part.content)
+	}
+	mw.Close()
+
+	t.Run("routed parts", func(t *testing.T) {
+		buffers := map[string]*bytes.Buffer{"first": {}, "second": {}}
+
+		n, err := CopyMultipart(context.Background(), bytes.NewReader(body.Bytes()), mw.Boundary(), func(headers textproto.MIMEHeader) (io.Writer, error) {
+			for name, buf := range buffers {
+				if strings.Contains(headers.Get("Content-Disposition"), `name="`+name+`"`) {
+					return buf, nil
+				}
+			}
+			return nil, errors.New("unexpected part")
+		}, BufferSize(16))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		if buffers["first"].String() != "hello world" {
+			t.Fatalf("expected first part to be %q but got %q", "hello world", buffers["first"].String())
+		}
+		if expected := strings.Repeat("multipart ", 100); buffers["second"].String() != expected {
+			t.Fatalf("expected second part to be copied but got %q", buffers["second"].String())
+		}
+		if n != 11+1000 {
+			t.Fatalf("expected n to be %d but got %d", 11+1000, n)
+		}
+	})
+
+	t.Run("skipped part and part error", func(t *testing.T) {
+		partErr := errors.New("no destination")
+
+		var calls int
+		n, err := CopyMultipart(context.Background(), bytes.NewReader(body.Bytes()), mw.Boundary(), func(headers textproto.MIMEHeader) (io.Writer, error) {
+			calls++
+			if calls == 1 {
+				return nil, nil
+			}
+			return nil, partErr
+		})
+		if !errors.Is(err, partErr) {
+			t.Fatalf("expected err to be %#q but got %#q", partErr, err)
+		}
+		if n != 0 || calls != 2 {
+			t.Fatalf("expected nothing to be copied over 2 parts but got %d bytes over %d", n, calls)
+		}
+	})
+
+	t.Run("canceled while parsing", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		block := make(chan struct{})
+		defer close(block)
+
+		_, err := CopyMultipart(ctx, ReaderFunc(func(b []byte) (int, error) {
+			<-block
+			return 0, io.EOF
+		}), mw.Boundary(), func(headers textproto.MIMEHeader) (io.Writer, error) {
+			return io.Discard, nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// CopyMultipartPart copies the body of a multipart part from src into dst like Copy, src being positioned at the
+// start of the body, past the part headers. The copy stops before the delimiter ending the body, that is a newline,
+// "\r\n" or "\n", followed by "--" and boundary, found even when split across reads. It fails with
+// io.ErrUnexpectedEOF if src ends before the delimiter. The delimiter and the following parts are left unread where
+// possible: if src is a *bufio.Reader, or implements Peek, Discard and Buffered likewise, data is only consumed once
+// known to belong to the body, and otherwise, if src implements io.Seeker, it is seeked back to the start of the
+// delimiter. Other readers may have consumed up to one buffer of data past the body.
+func CopyMultipartPart(ctx context.Context, dst io.Writer, src io.Reader, boundary string, opts ...CopyOption) (int64, error) {
+	var window partWindow
+	if pk, ok := src.(peeker); ok {
+		window = &peekWindow{r: pk}
+	} else {
+		window = &readWindow{r: src}
+	}
+	return Copy(ctx, dst, &partReader{window: window, delim: []byte("\n--" + boundary)}, opts...)
+}
+
+// partWindow gives access to the data of a reader ahead of what has been consumed.
+type partWindow interface {
+	// data returns the bytes available past what has been consumed.
+	data() []byte
+	// more makes more bytes available.
+	more() error
+	consume(n int)
+	// rewind positions the reader back to the first byte not consumed, if possible.
+	rewind() error
+}
+
+// partReader reads from a window up to the delimiter of a multipart body.
+type partReader struct {
+	window partWindow
+	delim  []byte
+	done   bool
+}
+
+func (pr *partReader) Read(p []byte) (int, error) {
+	if pr.done {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		data := pr.window.data()
+
+		if i := bytes.Index(data, pr.delim); i >= 0 {
+			if i > 0 && data[i-1] == '\r' {
+				i--
+			}
+			if i == 0 {
+				pr.done = true
+				if err := pr.window.rewind(); err != nil {
+					return 0, err
+				}
+				return 0, io.EOF
+			}
+			n := copy(p, data[:i])
+			pr.window.consume(n)
+			return n, nil
+		}
+
+		// The tail may hold the start of the delimiter, preceded by a carriage return.
+		if safe := len(data) - len(pr.delim); safe > 0 {
+			n := copy(p, data[:safe])
+			pr.window.consume(n)
+			return n, nil
+		}
+
+		if err := pr.window.more(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+	}
+}
+
+// peeker is implemented by *bufio.Reader.
+type peeker interface {
+	io.Reader
+	Peek(n int) ([]byte, error)
+	Discard(n int) (int, error)
+	Buffered() int
+}
+
+// peekWindow uses the buffer of a peeker as window, so that nothing is consumed before it is needed.
+type peekWindow struct {
+	r peeker
+}
+
+func (w *peekWindow) data() []byte {
+	data, _ := w.r.Peek(w.r.Buffered())
+	return data
+}
+
+func (w *peekWindow) more() error {
+	_, err := w.r.Peek(w.r.Buffered() + 1)
+	return err
+}
+
+func (w *peekWindow) consume(n int) { w.r.Discard(n) }
+
+func (w *peekWindow) rewind() error { return nil }
+
+// readWindow reads ahead into a buffer of its own, seeking back over it on rewind if the reader is an io.Seeker.
+type readWindow struct {
+	r       io.Reader
+	pending []byte
+	buf     []byte
+}
+
+func (w *readWindow) data() []byte { return w.pending }
+
+func (w *readWindow) more() error {
+	if w.buf == nil {
+		w.buf = make([]byte, defaultBufferSize)
+	}
+	n, err := w.r.Read(w.buf)
+	w.pending = append(w.pending, w.buf[:n]...)
+	if n > 0 {
+		return nil
+	}
+	return err
+}
+
+func (w *readWindow) consume(n int) { w.pending = w.pending[n:] }
+
+func (w *readWindow) rewind() error {
+	seeker, ok := w.r.(io.Seeker)
+	if !ok || len(w.pending) == 0 {
+		return nil
+	}
+	_, err := seeker.Seek(-int64(len(w.pending)), io.SeekCurrent)
+	return err
+}
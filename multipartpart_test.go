@@ -0,0 +1,106 @@
+package xio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCopyMultipartPart(t *testing.T) {
+	const boundary = "xio-boundary"
+
+	body := "--" + boundary + "\r\n" +
+		"Content-Disposition: form-data; name=\"first\"\r\n\r\n" +
+		"first body\r\nwith a line break and a fake \r\n--xio-boundar delimiter\r\n" +
+		"--" + boundary + "\r\n" +
+		"Content-Disposition: form-data; name=\"second\"\r\n\r\n" +
+		"second body\n" +
+		"--" + boundary + "--\r\n"
+
+	const first = "first body\r\nwith a line break and a fake \r\n--xio-boundar delimiter"
+	const second = "second body"
+
+	// skipHeaders reads past the next delimiter, up to the empty line ending the headers of the part.
+	skipHeaders := func(t *testing.T, r *bufio.Reader) {
+		t.Helper()
+		var delimited bool
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if strings.HasPrefix(line, "--"+boundary) {
+				delimited = true
+			} else if delimited && line == "\r\n" {
+				return
+			}
+		}
+	}
+
+	t.Run("bufio reader", func(t *testing.T) {
+		for _, src := range []struct {
+			kind string
+			r    io.Reader
+		}{
+			{kind: "whole", r: strings.NewReader(body)},
+			// delimiters are split across reads
+			{kind: "one byte at a time", r: iotest.OneByteReader(strings.NewReader(body))},
+		} {
+			r := bufio.NewReaderSize(src.r, 16)
+
+			var parts []string
+			for _, expected := range []string{first, second} {
+				skipHeaders(t, r)
+
+				var dst bytes.Buffer
+				n, err := CopyMultipartPart(context.Background(), &dst, r, boundary, BufferSize(5))
+				if err != nil {
+					t.Fatalf("%s: expected err to be nil but got %#q", src.kind, err)
+				}
+				if n != int64(len(expected)) {
+					t.Fatalf("%s: expected n to be %d but got %d", src.kind, len(expected), n)
+				}
+				parts = append(parts, dst.String())
+			}
+
+			if parts[0] != first || parts[1] != second {
+				t.Fatalf("%s: expected parts %q and %q but got %q", src.kind, first, second, parts)
+			}
+
+			rest, _ := io.ReadAll(r)
+			if string(rest) != "\n--"+boundary+"--\r\n" {
+				t.Fatalf("%s: expected the closing delimiter to be left unread but got %q", src.kind, rest)
+			}
+		}
+	})
+
+	t.Run("seeker", func(t *testing.T) {
+		src := strings.NewReader(body)
+		start := strings.Index(body, "first body")
+		src.Seek(int64(start), io.SeekStart)
+
+		var dst bytes.Buffer
+		if _, err := CopyMultipartPart(context.Background(), &dst, src, boundary); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if dst.String() != first {
+			t.Fatalf("expected %q but got %q", first, dst.String())
+		}
+
+		rest, _ := io.ReadAll(src)
+		if expected := body[start+len(first):]; string(rest) != expected {
+			t.Fatalf("expected src to be seeked back to the delimiter but %q is left", rest)
+		}
+	})
+
+	t.Run("missing delimiter", func(t *testing.T) {
+		_, err := CopyMultipartPart(context.Background(), io.Discard, bufio.NewReader(strings.NewReader("truncated body\r\n--xio")), boundary)
+		if err != io.ErrUnexpectedEOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrUnexpectedEOF, err)
+		}
+	})
+}
@@ -0,0 +1,14 @@
+package xio
+
+import (
+	"io"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeReader returns a reader that applies the Unicode normalization form, such as norm.NFC, to the text read
+// from r. Characters and combining marks split across reads from r are held back until the next starter is read, so
+// the output is normalized regardless of how r delivers the data.
+func NormalizeReader(r io.Reader, form norm.Form) io.Reader {
+	return form.Reader(r)
+}
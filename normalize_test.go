@@ -0,0 +1,41 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestNormalizeReader(t *testing.T) {
+	// é and ṩ decomposed into their base letter and combining marks.
+	decomposed := "cafe\u0301 s\u0323\u0307 done"
+	composed := "caf\u00e9 \u1e69 done"
+
+	for _, tc := range []struct {
+		name string
+		src  string
+	}{
+		{name: "decomposed across read boundaries", src: decomposed},
+		{name: "already composed", src: composed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var dst bytes.Buffer
+			_, err := Copy(
+				context.Background(),
+				&dst,
+				NormalizeReader(iotest.OneByteReader(strings.NewReader(tc.src)), norm.NFC),
+				BufferSize(2),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if dst.String() != composed {
+				t.Fatalf("expected %q but got %q", composed, dst.String())
+			}
+		})
+	}
+}
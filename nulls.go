@@ -0,0 +1,45 @@
+package xio
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrTooManyNulls is returned by a MaxNullReader when more null bytes than allowed pass through it.
+var ErrTooManyNulls = errors.New("too many null bytes")
+
+// MaxNullReader returns a reader that passes through data from r and fails with an *OffsetError wrapping
+// ErrTooManyNulls on the null byte that exceeds max null bytes in total, counted across reads. This is a simple
+// heuristic to reject binary data on a text channel. The bytes preceding the offending null byte are returned along
+// with the error.
+func MaxNullReader(r io.Reader, max int) io.Reader {
+	return &maxNullReader{r: r, max: max}
+}
+
+type maxNullReader struct {
+	r      io.Reader
+	max    int
+	nulls  int
+	offset int64
+	err    error
+}
+
+func (mr *maxNullReader) Read(p []byte) (int, error) {
+	if mr.err != nil {
+		return 0, mr.err
+	}
+
+	n, err := mr.r.Read(p)
+	for i, b := range p[:n] {
+		if b != 0 {
+			continue
+		}
+		if mr.nulls++; mr.nulls > mr.max {
+			mr.err = &OffsetError{Err: ErrTooManyNulls, Offset: mr.offset + int64(i)}
+			mr.offset += int64(i)
+			return i, mr.err
+		}
+	}
+	mr.offset += int64(n)
+	return n, err
+}
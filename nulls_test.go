@@ -0,0 +1,44 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMaxNullReader(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, MaxNullReader(strings.NewReader("plain text\x00\n"), 1), BufferSize(4))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 12 || dst.String() != "plain text\x00\n" {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+
+	t.Run("binary blob", func(t *testing.T) {
+		blob := []byte{'E', 'L', 'F', 0, 0, 1, 0, 0, 0, 0}
+
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, MaxNullReader(bytes.NewReader(blob), 3), BufferSize(4))
+
+		if !errors.Is(err, ErrTooManyNulls) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrTooManyNulls, err)
+		}
+
+		var offsetErr *OffsetError
+		if !errors.As(err, &offsetErr) {
+			t.Fatalf("expected an offset error but got %T", err)
+		}
+		if offsetErr.Offset != 7 {
+			t.Fatalf("expected offset to be 7 but got %d", offsetErr.Offset)
+		}
+		if n != 7 || !bytes.Equal(dst.Bytes(), blob[:7]) {
+			t.Fatalf("expected the bytes before the offending null to be copied but got %d bytes: %q", n, dst.Bytes())
+		}
+	})
+}
@@ -1,9 +1,63 @@
 package xio
 
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// ErrMACMismatch is returned by Copy when the data copied doesn't match the MAC given to VerifyHMAC.
+var ErrMACMismatch = errors.New("MAC mismatch")
+
 type copyoptions struct {
 	WaitForLastOp bool
 	bufferSize    int
 	buffer        []byte
+
+	flushEveryChunk bool
+	writers         []func(io.Writer) io.Writer
+	taps            []func() tap
+	loadOffset      func() (int64, error)
+	saveOffset      func(offset int64) error
+	rate            *rateRamp
+	sink            func(dst io.Writer) io.Writer
+	stall           *stallDetection
+	wouldBlock      wouldBlockHandling
+	maxBytes        int64
+	progress        func(written, total int64)
+	predictSize     func(sofar int64, rate float64) int64
+	readAhead       int
+	retryAfter      func(error) (time.Duration, bool)
+	allowEmptyFlush bool
+	onComplete      func(n int64, err error)
+	onBufferFill    func(fillRatio float64)
+	coalesceChunks  int
+	maxMemory       int
+	register        bool
+	idempotentKey   func(chunk []byte) string
+	flushOnIdle     time.Duration
+	bufferPoolKey   any
+	slowestOp       func(kind string, d time.Duration)
+	lockTimeout     time.Duration
+	recoverPanics   bool
+	progressPath    string
+	progressEvery   time.Duration
+	chunkDelay      time.Duration
+	watermark       func(committed int64)
+	sharedLimiter   *SharedLimiter
+	priority        int
+	hooks           Hooks
+	drainMax        int64
+	concurrent      bool
+	logger          *slog.Logger
+	// invalid is the error of an option given an invalid argument, failing the copy before it starts.
+	invalid error
 }
 
 type CopyOption func(*copyoptions)
@@ -25,3 +79,536 @@ func Buffer(b []byte) CopyOption {
 		c.buffer = b
 	}
 }
+
+// BufferFromContext takes the buffer of the copy from the *sync.Pool stored in the context under key, so that request
+// scoped pools integrate without being threaded through every call. The pool must hold []byte or *[]byte values, and
+// the buffer is put back once the copy goroutine is done with it, unless ReadAhead is used since src may still be
+// reading into it. When the context holds no pool, or the pool returns nil, the buffer is allocated as usual. The
+// Buffer option takes precedence.
+func BufferFromContext(key any) CopyOption {
+	return func(c *copyoptions) {
+		c.bufferPoolKey = key
+	}
+}
+
+// MaxBytes bounds the amount of data read from src. If src holds more than n bytes, the first n bytes are copied and
+// the copy fails with ErrMaxBytesExceeded. A value of zero or less means no limit, which is the default.
+func MaxBytes(n int64) CopyOption {
+	return func(c *copyoptions) {
+		c.maxBytes = n
+	}
+}
+
+// FlushEveryChunk flushes dst after every chunk written to it when dst implements Flusher, trading throughput
+// for latency. A flush error aborts the copy.
+func FlushEveryChunk() CopyOption {
+	return func(c *copyoptions) {
+		c.flushEveryChunk = true
+	}
+}
+
+// FilterLines only writes the lines of src for which keep returns true, turning Copy into a streaming grep.
+// Lines are passed to keep without their terminator but kept lines are written with it. When FilterLines is used
+// n counts the bytes of the kept lines.
+func FilterLines(keep func(line []byte) bool) CopyOption {
+	return func(c *copyoptions) {
+		c.writers = append(c.writers, func(w io.Writer) io.Writer {
+			return &lineWriter{w: w, fn: func(w io.Writer, line []byte) error {
+				if !keep(trimLineTerminator(line)) {
+					return nil
+				}
+				_, err := w.Write(line)
+				return err
+			}}
+		})
+	}
+}
+
+// FlushOnIdle flushes dst when it implements Flusher once no data has been read from src for d since the last
+// write, so that buffered partial lines of an interactive stream reach the client while the input pauses. Unlike
+// FlushEveryChunk, bursts of data are not flushed chunk by chunk. A flush error aborts the copy.
+func FlushOnIdle(d time.Duration) CopyOption {
+	return func(c *copyoptions) {
+		c.flushOnIdle = d
+	}
+}
+
+// DedupConsecutiveLines drops the lines of src identical to the line before them, like uniq, handling lines split
+// across reads. Lines are compared without their terminator. When count is true, every remaining line is prefixed with
+// its number of consecutive occurrences and a space, as with uniq -c, and is only written once the next different line
+// is read or src is exhausted. When DedupConsecutiveLines is used n counts the bytes written, prefixes included.
+func DedupConsecutiveLines(count bool) CopyOption {
+	return func(c *copyoptions) {
+		c.writers = append(c.writers, func(w io.Writer) io.Writer {
+			return newUniqWriter(w, count)
+		})
+	}
+}
+
+// Entropy computes a Shannon entropy estimate of the bytes written to dst and reports it in bits per byte,
+// between 0 and 8, once the copy completes successfully. Values close to 8 indicate data that is already
+// compressed or encrypted and unlikely to benefit from compression.
+func Entropy(fn func(bitsPerByte float64)) CopyOption {
+	return func(c *copyoptions) {
+		c.taps = append(c.taps, func() tap {
+			var histogram [256]int64
+			return tap{
+				write: func(p []byte) {
+					for _, b := range p {
+						histogram[b]++
+					}
+				},
+				done: func() error {
+					fn(entropy(&histogram))
+					return nil
+				},
+			}
+		})
+	}
+}
+
+// LoadOffset resumes a copy from the offset returned by fn, typically one persisted by SaveOffset in a previous run.
+// Copy seeks src forward by offset bytes if it implements io.Seeker, and otherwise reads and discards them.
+// Positioning dst, for example by opening it in append mode, is left to the caller. The returned n only counts
+// the bytes written during this run.
+func LoadOffset(fn func() (int64, error)) CopyOption {
+	return func(c *copyoptions) {
+		c.loadOffset = fn
+	}
+}
+
+// SaveOffset calls fn after every chunk written to dst with the offset in src up to which data has been handed to
+// dst, including any offset given by LoadOffset. An error returned by fn aborts the copy.
+func SaveOffset(fn func(offset int64) error) CopyOption {
+	return func(c *copyoptions) {
+		c.saveOffset = fn
+	}
+}
+
+// ResumeFrom resumes a copy from a token saved by CopyCheckpointed, like LoadOffset does from an offset. The copy fails
+// with ErrInvalidToken if the token cannot be decoded.
+func ResumeFrom(token []byte) CopyOption {
+	return LoadOffset(func() (int64, error) {
+		return parseResumeToken(token)
+	})
+}
+
+// CoalesceWrites buffers up to maxChunks read results and writes them to dst at once as net.Buffers. If dst is
+// a *net.TCPConn, or implements BuffersWriter, this results in a single vectored write. Other destinations receive the
+// chunks as sequential writes. Chunks still buffered when src is exhausted are written before Copy returns.
+// Each buffered chunk holds a copy of the data, using up to maxChunks times the buffer size of memory.
+func CoalesceWrites(maxChunks int) CopyOption {
+	return func(c *copyoptions) {
+		// The number of chunks is read when the writer is created since MaxMemory may lower it.
+		if c.coalesceChunks == 0 {
+			c.writers = append(c.writers, func(w io.Writer) io.Writer {
+				return &coalescingWriter{w: w, max: c.coalesceChunks}
+			})
+		}
+		c.coalesceChunks = maxChunks
+	}
+}
+
+// RateLimit throttles the copy to bytesPerSecond. It is equivalent to RateRamp(bytesPerSecond, bytesPerSecond, 0), so
+// a non-positive rate means no limit.
+func RateLimit(bytesPerSecond int64) CopyOption {
+	return RateRamp(bytesPerSecond, bytesPerSecond, 0)
+}
+
+// RateRamp throttles the copy to a rate that increases linearly from start to target bytes per second over the
+// given duration, and then stays at target. This avoids a sudden load spike at the beginning of a transfer, much like
+// a slow start. Copy waits, cancelably, between chunks whenever it is ahead of the allowed rate, so a single chunk
+// may be written in a burst of up to the buffer size. A non-positive target means no limit and removes any rate set
+// by a previous option, while a negative start is taken as 0.
+func RateRamp(start, target int64, over time.Duration) CopyOption {
+	return func(c *copyoptions) {
+		if target <= 0 {
+			c.rate = nil
+			return
+		}
+		c.rate = &rateRamp{start: float64(max(start, 0)), target: float64(target), over: over.Seconds()}
+	}
+}
+
+// SharedLimit throttles the copy along with every other copy given the same SharedLimiter, so that their combined
+// rate stays within its limit. Copy waits, cancelably, for its turn before writing every chunk, so a single chunk may
+// be written in a burst of up to the buffer size.
+func SharedLimit(l *SharedLimiter) CopyOption {
+	return func(c *copyoptions) {
+		c.sharedLimiter = l
+	}
+}
+
+// WithPriority sets the priority class of the copy within its SharedLimiter, 0 by default. Under contention, copies
+// get a share of the bandwidth proportional to their class plus one. Negative classes are treated as 0.
+func WithPriority(class int) CopyOption {
+	return func(c *copyoptions) {
+		c.priority = class
+	}
+}
+
+// ContentDefinedChunks splits the copied stream at content defined boundaries and calls fn with each chunk instead of
+// writing to dst, which is ignored. The returned n counts the bytes passed to fn. Boundaries only depend on the
+// content, not on how src delivers it, so identical content yields identical chunks even when shifted within the
+// stream, which is the basis of variable size deduplication. See MinChunkSize for the rolling hash parameters. The chunk
+// passed to fn is only valid for the duration of the call. An error returned by fn aborts the copy.
+func ContentDefinedChunks(fn func(chunk []byte) error) CopyOption {
+	return func(c *copyoptions) {
+		c.sink = func(io.Writer) io.Writer {
+			return &chunker{fn: fn}
+		}
+	}
+}
+
+// DetectStall sets a write deadline of timeout on every write when dst supports write deadlines, like a net.Conn.
+// A write that times out is retried, and if maxStalls consecutive attempts time out without writing a single byte the
+// peer is considered stalled, for example because its TCP receive window is closed, and the copy is aborted with
+// ErrPeerStalled. A peer that is slow but still accepting some data is not considered stalled.
+func DetectStall(timeout time.Duration, maxStalls int) CopyOption {
+	return func(c *copyoptions) {
+		c.stall = &stallDetection{timeout: timeout, maxStalls: maxStalls}
+	}
+}
+
+// HandleEAGAIN makes Copy retry writes that fail because dst would block, as non-blocking sockets do by returning
+// EAGAIN, instead of aborting. Before retrying Copy waits for dst to become writable using the func given to
+// WaitWritable, or polls every millisecond when there is none. Would block errors are recognized with errors.Is
+// against syscall.EAGAIN, or against the sentinel given to WouldBlockError. On platforms without EAGAIN, such as
+// plan9, only the sentinel given to WouldBlockError is recognized.
+func HandleEAGAIN(value bool) CopyOption {
+	return func(c *copyoptions) {
+		c.wouldBlock.enabled = value
+	}
+}
+
+// WouldBlockError sets the sentinel error that identifies would block errors for HandleEAGAIN.
+func WouldBlockError(sentinel error) CopyOption {
+	return func(c *copyoptions) {
+		c.wouldBlock.sentinel = sentinel
+	}
+}
+
+// WaitWritable sets the readiness func used by HandleEAGAIN. It should block until dst is writable, typically by
+// integrating with a poller, and return early with an error when the context is canceled. An error returned by fn
+// aborts the copy.
+func WaitWritable(fn func(ctx context.Context) error) CopyOption {
+	return func(c *copyoptions) {
+		c.wouldBlock.waitWritable = fn
+	}
+}
+
+// ShardOutput splits the copied stream into shards of up to shardSize bytes instead of writing to dst, which is
+// ignored. open is called with the index of each shard, starting at 0, to get its writer, and every shard is closed
+// before the next one is opened. The last shard is closed when the copy ends, whatever the outcome. A close error is
+// returned unless the copy already failed with another error. A non-positive shardSize means no limit, the whole
+// stream going to a single shard.
+func ShardOutput(shardSize int64, open func(index int) (io.WriteCloser, error)) CopyOption {
+	return func(c *copyoptions) {
+		c.sink = func(io.Writer) io.Writer {
+			return &shardWriter{size: shardSize, open: open}
+		}
+	}
+}
+
+// EnsureTrailingNewline writes a newline to dst once src is exhausted if the last byte written wasn't already one,
+// which is useful when concatenating text files. Nothing is appended when nothing was written. The appended newline
+// counts towards n.
+func EnsureTrailingNewline() CopyOption {
+	return func(c *copyoptions) {
+		c.writers = append(c.writers, func(w io.Writer) io.Writer {
+			return &trailingNewlineWriter{w: w}
+		})
+	}
+}
+
+// Progress calls fn after every chunk with the number of bytes written to dst so far, and the expected total as
+// predicted by PredictSize, or -1 when it is unknown.
+func Progress(fn func(written, total int64)) CopyOption {
+	return func(c *copyoptions) {
+		c.progress = fn
+	}
+}
+
+// PredictSize calls fn after every chunk with the number of bytes written to dst so far and the average rate in
+// bytes per second since the copy started, so that callers can estimate the total size or time to completion.
+// The returned prediction is passed as the total to the Progress callback.
+func PredictSize(fn func(sofar int64, rate float64) int64) CopyOption {
+	return func(c *copyoptions) {
+		c.predictSize = fn
+	}
+}
+
+// ReadAhead pipelines reads and writes by reading from src on a separate goroutine into a pool of n buffers, so that
+// up to n chunks can be read ahead while dst is busy. This helps when both src and dst are slow. Memory is bounded by
+// n times the buffer size, the buffer given by the Buffer option being one of them. When the copy is canceled or
+// fails, chunks read ahead are discarded and Copy returns without waiting for a pending read on src.
+func ReadAhead(n int) CopyOption {
+	return func(c *copyoptions) {
+		c.readAhead = n
+	}
+}
+
+// RetryAfter honors the backpressure of servers that hint when to try again, like the Retry-After header of HTTP.
+// When a read from src or a write to dst fails with an error for which extract returns a duration and true, Copy waits
+// for that duration, cancelably, and retries the operation, resuming the copy where it stopped. Other errors abort the
+// copy as usual.
+func RetryAfter(extract func(error) (time.Duration, bool)) CopyOption {
+	return func(c *copyoptions) {
+		c.retryAfter = extract
+	}
+}
+
+// AllowEmptyFlush forwards empty reads from src to dst as zero-length writes once some data has been copied, for
+// writers that treat a zero-length write as a flush signal, as some codecs do. src signals a flush by returning no data
+// and no error from Read, typically when it re-chunks the stream. Without this option empty reads are skipped.
+func AllowEmptyFlush() CopyOption {
+	return func(c *copyoptions) {
+		c.allowEmptyFlush = true
+	}
+}
+
+// MerkleTree hashes every blockSize bytes written to dst with SHA-256, the last block possibly being shorter, and
+// calls fn with the root of the binary Merkle tree built over the block hashes once the copy completes successfully.
+// The tree is built as described by MerkleRoot: adjacent nodes are hashed together in pairs, a node without a sibling is
+// promoted as is, and the root of no data is the SHA-256 of nothing. A non-positive blockSize fails the copy before
+// anything is read.
+func MerkleTree(blockSize int, fn func(root []byte)) CopyOption {
+	if blockSize <= 0 {
+		return func(c *copyoptions) {
+			c.invalid = fmt.Errorf("xio: MerkleTree: invalid block size %d", blockSize)
+		}
+	}
+	return withTap(func() tap {
+		leaves := &merkleLeaves{size: blockSize, h: sha256.New()}
+		return tap{
+			write: leaves.write,
+			done: func() error {
+				leaves.done()
+				fn(MerkleRoot(leaves.hashes, sha256.New))
+				return nil
+			},
+		}
+	})
+}
+
+// OnComplete calls fn exactly once when the copy finishes, whether it succeeds, fails or is canceled, with the values
+// returned by Copy. This gives a single place for cleanup or metrics regardless of the outcome. With WaitForLastOp(false)
+// fn is called once the copy goroutine has settled, possibly after Copy returned, with the final number of bytes
+// written to dst.
+func OnComplete(fn func(n int64, err error)) CopyOption {
+	return func(c *copyoptions) {
+		c.onComplete = fn
+	}
+}
+
+// OnBufferFill calls fn after every read from src that returned data with how full the read buffer was, between 0 and
+// 1. Ratios consistently close to 1 suggest the buffer is too small, and consistently low ratios that it is bigger than
+// src can fill.
+func OnBufferFill(fn func(fillRatio float64)) CopyOption {
+	return func(c *copyoptions) {
+		c.onBufferFill = fn
+	}
+}
+
+// MaxMemory bounds the memory used by a single Copy for its buffers: the read buffer, the buffers of ReadAhead, and the
+// chunks held by CoalesceWrites, each the size of the read buffer. When the configuration exceeds the cap, the ReadAhead
+// depth is lowered first, then the number of chunks coalesced, down to one each, and finally the buffer size, whether
+// it comes from BufferSize or its default. A buffer given by the Buffer option is not reallocated, only a prefix of it
+// is used. Memory used by other options, or by src and dst, is not accounted for.
+func MaxMemory(bytes int) CopyOption {
+	return func(c *copyoptions) {
+		c.maxMemory = bytes
+	}
+}
+
+// HMAC computes an HMAC of the bytes written to dst with key and the hash h, such as sha256.New, and calls fn with the
+// MAC once the copy completes successfully.
+func HMAC(key []byte, h func() hash.Hash, fn func(mac []byte)) CopyOption {
+	return withTap(func() tap {
+		mac := hmac.New(h, key)
+		return tap{
+			write: func(p []byte) { mac.Write(p) },
+			done: func() error {
+				fn(mac.Sum(nil))
+				return nil
+			},
+		}
+	})
+}
+
+// VerifyHMAC computes an HMAC of the bytes written to dst like HMAC, and fails the copy with ErrMACMismatch once src
+// is exhausted if it differs from expected. The MAC is compared in constant time. Since the data has already been
+// written to dst by then, dst should not be trusted until Copy returns successfully.
+func VerifyHMAC(key []byte, h func() hash.Hash, expected []byte) CopyOption {
+	return withTap(func() tap {
+		mac := hmac.New(h, key)
+		return tap{
+			write: func(p []byte) { mac.Write(p) },
+			done: func() error {
+				if !hmac.Equal(mac.Sum(nil), expected) {
+					return ErrMACMismatch
+				}
+				return nil
+			},
+		}
+	})
+}
+
+// ManifestEntry records the position of a chunk written to dst.
+type ManifestEntry struct {
+	Offset int64
+	Length int64
+}
+
+// Manifest records the offset and length of every chunk written to dst, and calls fn with the entries once the copy
+// completes successfully, for example to build an index for random access. Chunks coalesced by CoalesceWrites are
+// recorded individually, even when written to dst at once.
+func Manifest(fn func(entries []ManifestEntry)) CopyOption {
+	return withTap(func() tap {
+		var entries []ManifestEntry
+		var offset int64
+		return tap{
+			write: func(p []byte) {
+				if len(p) == 0 {
+					return
+				}
+				entries = append(entries, ManifestEntry{Offset: offset, Length: int64(len(p))})
+				offset += int64(len(p))
+			},
+			done: func() error {
+				fn(entries)
+				return nil
+			},
+		}
+	})
+}
+
+// Register adds the copy to the registry of active copies listed by ActiveCopies for as long as it is in flight. With
+// WaitForLastOp(false) a canceled copy stays registered until its goroutine settles.
+func Register() CopyOption {
+	return func(c *copyoptions) {
+		c.register = true
+	}
+}
+
+// IdempotentWrites writes every chunk to dst with WriteKeyed when dst implements KeyedWriter, along with the key
+// returned by keyFn for the chunk. A chunk retried after a failed write, for example by RetryAfter, gets the same key,
+// letting dst ignore chunks it already applied when the failure happened after it did. The option has no effect
+// when dst does not implement KeyedWriter.
+func IdempotentWrites(keyFn func(chunk []byte) string) CopyOption {
+	return func(c *copyoptions) {
+		c.idempotentKey = keyFn
+	}
+}
+
+// SlowestOp tracks the longest single read from src and write to dst, and reports them once the copy ends, whatever
+// the outcome, by calling fn with the kind "read" and then "write". Comparing them helps identify whether src or dst is
+// the bottleneck. Durations are zero when no such operation happened.
+func SlowestOp(fn func(kind string, d time.Duration)) CopyOption {
+	return func(c *copyoptions) {
+		c.slowestOp = fn
+	}
+}
+
+// LockTimeout bounds how long CopyToLockedFile waits for the lock on its file before failing with ErrLockTimeout.
+// By default it waits until the context is canceled. Other copy functions ignore it.
+func LockTimeout(d time.Duration) CopyOption {
+	return func(c *copyoptions) {
+		c.lockTimeout = d
+	}
+}
+
+// RecoverPanics recovers a panic raised while copying, such as from a misbehaving src or dst, and fails the copy with a
+// *PanicError matching ErrPanic instead of crashing the program. Panics in callbacks run during the copy are recovered
+// too.
+func RecoverPanics() CopyOption {
+	return func(c *copyoptions) {
+		c.recoverPanics = true
+	}
+}
+
+// ProgressFile writes the number of bytes written to dst to the file at path when the copy starts, then every interval,
+// and once more when it ends, so that external tools can monitor a batch job by polling the file. The file holds
+// "written=<bytes>" lines, followed by "total=<bytes>" and "percent=<percentage>" lines when the size of src is known,
+// that is when src is an *io.LimitedReader or has a Len method like *bytes.Reader. Every update is written to a
+// temporary file renamed over path, so readers never see a partial update. Failing to write the file doesn't fail the
+// copy.
+func ProgressFile(path string, every time.Duration) CopyOption {
+	return func(c *copyoptions) {
+		c.progressPath = path
+		c.progressEvery = every
+	}
+}
+
+// ChunkDelay waits for d after every chunk, cancelably, before reading the next one. It is a simple throttling aid
+// meant for tests and demos of progress reporting, backpressure or cancelation, see RateLimit to throttle to a rate.
+func ChunkDelay(d time.Duration) CopyOption {
+	return func(c *copyoptions) {
+		c.chunkDelay = d
+	}
+}
+
+// Watermark syncs dst after every chunk reaching it when dst implements Syncer (Sync() error), such as *os.File, and
+// then calls fn with the offset known to be persisted, that is the offset loaded by LoadOffset plus the bytes written
+// to dst. After a crash, the copy can resume from the last watermark. A failed sync aborts the copy without advancing
+// the watermark. fn is never called when dst doesn't implement Syncer.
+func Watermark(fn func(committed int64)) CopyOption {
+	return func(c *copyoptions) {
+		c.watermark = fn
+	}
+}
+
+// Hooks are functions called by Copy around every read of a chunk from src and every write of a chunk to dst, for
+// fault injection and instrumentation. Every hook is optional, and an error returned by any of them aborts the copy
+// with it. Hooks see the chunks exchanged by Copy itself: writes go through the writer options, such as FilterLines,
+// before reaching dst, and with ReadAhead reads happen ahead of time, the read hooks being called as chunks are taken.
+type Hooks struct {
+	// BeforeRead is called before reading a chunk.
+	BeforeRead func() error
+	// AfterRead is called with the chunk read and the read error, which aborts the copy regardless.
+	AfterRead func(chunk []byte, err error) error
+	// BeforeWrite is called with the chunk about to be written. It must not modify it.
+	BeforeWrite func(chunk []byte) error
+	// AfterWrite is called with the chunk written, and the result of the write, whose error aborts the copy regardless.
+	AfterWrite func(chunk []byte, n int, err error) error
+}
+
+// WithHooks installs hooks called around every read and write of the copy.
+func WithHooks(h Hooks) CopyOption {
+	return func(c *copyoptions) {
+		c.hooks = h
+	}
+}
+
+// DrainRemainder makes CopyN read and discard up to max bytes left in src once n bytes have been copied, cancelably,
+// so that a connection can be reused. A failed drain is reported by CopyN along with the n bytes copied. Other copy
+// functions ignore it.
+func DrainRemainder(max int64) CopyOption {
+	return func(c *copyoptions) {
+		c.drainMax = max
+	}
+}
+
+// Concurrent makes the copy count against the limit set by SetMaxConcurrent: before starting, Copy waits for a slot,
+// cancelably, and releases it once the copy ends.
+func Concurrent() CopyOption {
+	return func(c *copyoptions) {
+		c.concurrent = true
+	}
+}
+
+// WithLogger logs the start of the copy at debug level, and its end along with the bytes written and its duration, at
+// info level when it succeeds, warn level when it is canceled and error level when it fails. Records include the copy
+// ID set on the context with WithCopyID, if any.
+func WithLogger(l *slog.Logger) CopyOption {
+	return func(c *copyoptions) {
+		c.logger = l
+	}
+}
+
+// withTap installs a tap, for the functions of the package built on top of Copy.
+func withTap(newTap func() tap) CopyOption {
+	return func(c *copyoptions) {
+		c.taps = append(c.taps, newTap)
+	}
+}
@@ -0,0 +1,923 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+type flushWriter struct {
+	events   []string
+	flushErr error
+}
+
+func (w *flushWriter) Write(b []byte) (int, error) {
+	w.events = append(w.events, "write:"+string(b))
+	return len(b), nil
+}
+
+func (w *flushWriter) Flush() error {
+	w.events = append(w.events, "flush")
+	return w.flushErr
+}
+
+func TestFlushEveryChunk(t *testing.T) {
+	t.Run("flushes after every chunk", func(t *testing.T) {
+		dst := &flushWriter{}
+
+		n, err := Copy(context.Background(), dst, strings.NewReader("abcdefgh"), BufferSize(3), FlushEveryChunk())
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 8 {
+			t.Fatalf("expected n to be 8 but got %d", n)
+		}
+
+		expected := []string{"write:abc", "flush", "write:def", "flush", "write:gh", "flush"}
+		if !reflect.DeepEqual(dst.events, expected) {
+			t.Fatalf("expected events to be %v but got %v", expected, dst.events)
+		}
+	})
+
+	t.Run("does not flush without the option", func(t *testing.T) {
+		dst := &flushWriter{}
+
+		if _, err := Copy(context.Background(), dst, strings.NewReader("abcdef"), BufferSize(3)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		expected := []string{"write:abc", "write:def"}
+		if !reflect.DeepEqual(dst.events, expected) {
+			t.Fatalf("expected events to be %v but got %v", expected, dst.events)
+		}
+	})
+
+	t.Run("flush error aborts", func(t *testing.T) {
+		flushErr := errors.New("flush failed")
+		dst := &flushWriter{flushErr: flushErr}
+
+		n, err := Copy(context.Background(), dst, strings.NewReader("abcdef"), BufferSize(3), FlushEveryChunk())
+		if err != flushErr {
+			t.Fatalf("expected err to be %#q but got %#q", flushErr, err)
+		}
+		if n != 3 {
+			t.Fatalf("expected n to be 3 but got %d", n)
+		}
+	})
+}
+
+func TestFilterLines(t *testing.T) {
+	src := "INFO starting\nERROR disk full\nINFO retrying\r\nERROR disk still full\r\nINFO done\nERROR no newline"
+
+	var dst strings.Builder
+	n, err := Copy(
+		context.Background(),
+		&dst,
+		strings.NewReader(src),
+		BufferSize(4), // lines are split across several reads
+		FilterLines(func(line []byte) bool { return strings.HasPrefix(string(line), "ERROR") }),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+
+	expected := "ERROR disk full\nERROR disk still full\r\nERROR no newline"
+	if actual := dst.String(); actual != expected {
+		t.Fatalf("expected output to be %q but got %q", expected, actual)
+	}
+	if n != int64(len(expected)) {
+		t.Fatalf("expected n to be %d but got %d", len(expected), n)
+	}
+}
+
+func TestDedupConsecutiveLines(t *testing.T) {
+	src := "a\na\na\nb\na\nc\r\nc\nc\nd\nd"
+
+	for _, tc := range []struct {
+		name     string
+		count    bool
+		expected string
+	}{
+		{name: "without count", expected: "a\nb\na\nc\r\nd\n"},
+		{name: "with count", count: true, expected: "3 a\n1 b\n1 a\n3 c\r\n2 d\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var dst strings.Builder
+			n, err := Copy(
+				context.Background(),
+				&dst,
+				strings.NewReader(src),
+				BufferSize(3), // lines are split across several reads
+				DedupConsecutiveLines(tc.count),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if actual := dst.String(); actual != tc.expected {
+				t.Fatalf("expected output to be %q but got %q", tc.expected, actual)
+			}
+			if n != int64(len(tc.expected)) {
+				t.Fatalf("expected n to be %d but got %d", len(tc.expected), n)
+			}
+		})
+	}
+}
+
+func TestChunkDelay(t *testing.T) {
+	t.Run("completes", func(t *testing.T) {
+		const delay = 10 * time.Millisecond
+
+		var dst strings.Builder
+		start := time.Now()
+		n, err := Copy(context.Background(), &dst, strings.NewReader("hello world"), BufferSize(4), ChunkDelay(delay))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 || dst.String() != "hello world" {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+		}
+		// three chunks, each followed by a delay
+		if elapsed := time.Since(start); elapsed < 3*delay {
+			t.Fatalf("expected copy to take at least %v but took %v", 3*delay, elapsed)
+		}
+	})
+
+	t.Run("canceled during delay", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var dst strings.Builder
+		start := time.Now()
+		n, err := Copy(ctx, &dst, strings.NewReader("hello world"), BufferSize(4), ChunkDelay(time.Hour))
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("expected copy to return promptly but took %v", elapsed)
+		}
+		if n != 4 || dst.String() != "hell" {
+			t.Fatalf("expected the first chunk to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+}
+
+// syncWriter records its writes and syncs, failing the sync numbered failAt when set.
+type syncWriter struct {
+	bytes.Buffer
+	events []string
+	syncs  int
+	failAt int
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.events = append(w.events, "write")
+	return w.Buffer.Write(p)
+}
+
+func (w *syncWriter) Sync() error {
+	w.syncs++
+	if w.syncs == w.failAt {
+		w.events = append(w.events, "sync failed")
+		return errors.New("sync failed")
+	}
+	w.events = append(w.events, "sync")
+	return nil
+}
+
+func TestWatermark(t *testing.T) {
+	t.Run("advances after every sync", func(t *testing.T) {
+		dst := &syncWriter{}
+		n, err := Copy(context.Background(), dst, strings.NewReader("hello world"), BufferSize(4), Watermark(func(committed int64) {
+			dst.events = append(dst.events, fmt.Sprintf("watermark %d", committed))
+		}))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 {
+			t.Fatalf("expected n to be 11 but got %d", n)
+		}
+
+		expected := []string{"write", "sync", "watermark 4", "write", "sync", "watermark 8", "write", "sync", "watermark 11"}
+		if !reflect.DeepEqual(dst.events, expected) {
+			t.Fatalf("expected events %q but got %q", expected, dst.events)
+		}
+	})
+
+	t.Run("failed sync", func(t *testing.T) {
+		dst := &syncWriter{failAt: 2}
+		var watermarks []int64
+		_, err := Copy(context.Background(), dst, strings.NewReader("hello world"), BufferSize(4), Watermark(func(committed int64) {
+			watermarks = append(watermarks, committed)
+		}))
+		if err == nil || err.Error() != "sync failed" {
+			t.Fatalf("expected sync error but got %#q", err)
+		}
+		if !reflect.DeepEqual(watermarks, []int64{4}) {
+			t.Fatalf("expected watermark to stop at 4 but got %v", watermarks)
+		}
+	})
+
+	t.Run("offset", func(t *testing.T) {
+		var watermarks []int64
+		_, err := Copy(
+			context.Background(),
+			&syncWriter{},
+			strings.NewReader("hello world"),
+			BufferSize(4),
+			LoadOffset(func() (int64, error) { return 6, nil }),
+			Watermark(func(committed int64) { watermarks = append(watermarks, committed) }),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if !reflect.DeepEqual(watermarks, []int64{10, 11}) {
+			t.Fatalf("expected watermarks to include the offset but got %v", watermarks)
+		}
+	})
+
+	t.Run("dst without sync", func(t *testing.T) {
+		_, err := Copy(context.Background(), &bytes.Buffer{}, strings.NewReader("hello"), Watermark(func(committed int64) {
+			t.Errorf("expected watermark not to be called but got %d", committed)
+		}))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+	})
+}
+
+func TestWithHooks(t *testing.T) {
+	t.Run("abort after the second read", func(t *testing.T) {
+		injected := errors.New("injected fault")
+
+		var events []string
+		var reads int
+		var dst strings.Builder
+		n, err := Copy(context.Background(), &dst, strings.NewReader("hello world"), BufferSize(4), WithHooks(Hooks{
+			BeforeRead: func() error {
+				events = append(events, "before read")
+				return nil
+			},
+			AfterRead: func(chunk []byte, err error) error {
+				events = append(events, fmt.Sprintf("after read %q", chunk))
+				if reads++; reads == 2 {
+					return injected
+				}
+				return nil
+			},
+			BeforeWrite: func(chunk []byte) error {
+				events = append(events, fmt.Sprintf("before write %q", chunk))
+				return nil
+			},
+			AfterWrite: func(chunk []byte, n int, err error) error {
+				events = append(events, fmt.Sprintf("after write %d", n))
+				return nil
+			},
+		}))
+		if err != injected {
+			t.Fatalf("expected err to be %#q but got %#q", injected, err)
+		}
+		if n != 4 || dst.String() != "hell" {
+			t.Fatalf("expected the first chunk to be copied but got %d bytes: %q", n, dst.String())
+		}
+
+		expected := []string{
+			"before read", `after read "hell"`, `before write "hell"`, "after write 4",
+			"before read", `after read "o wo"`,
+		}
+		if !reflect.DeepEqual(events, expected) {
+			t.Fatalf("expected events %q but got %q", expected, events)
+		}
+	})
+
+	t.Run("abort before write", func(t *testing.T) {
+		injected := errors.New("injected fault")
+
+		var dst strings.Builder
+		_, err := Copy(context.Background(), &dst, strings.NewReader("hello"), WithHooks(Hooks{
+			BeforeWrite: func(chunk []byte) error { return injected },
+		}))
+		if err != injected {
+			t.Fatalf("expected err to be %#q but got %#q", injected, err)
+		}
+		if dst.Len() != 0 {
+			t.Fatalf("expected nothing to be written but got %q", dst.String())
+		}
+	})
+}
+
+func TestEntropy(t *testing.T) {
+	random := make([]byte, 64*1024)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatalf("failed to generate random data: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		data     []byte
+		min, max float64
+	}{
+		{name: "repeated byte", data: bytes.Repeat([]byte{'a'}, 4096), min: 0, max: 0},
+		{name: "two symbols", data: bytes.Repeat([]byte("ab"), 4096), min: 1, max: 1},
+		{name: "text", data: []byte(strings.Repeat("the quick brown fox jumps over the lazy dog ", 100)), min: 3, max: 5},
+		{name: "random", data: random, min: 7.9, max: 8},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			estimate := -1.0
+
+			_, err := Copy(
+				context.Background(),
+				io.Discard,
+				bytes.NewReader(tc.data),
+				Entropy(func(bitsPerByte float64) { estimate = bitsPerByte }),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if estimate < tc.min || estimate > tc.max {
+				t.Fatalf("expected entropy to be within [%v, %v] but got %v", tc.min, tc.max, estimate)
+			}
+		})
+	}
+}
+
+type suggestingReader struct {
+	suggestion int
+	readSizes  []int
+}
+
+func (r *suggestingReader) SuggestBufferSize() int { return r.suggestion }
+
+func (r *suggestingReader) Read(b []byte) (int, error) {
+	r.readSizes = append(r.readSizes, len(b))
+	return len(b), io.EOF
+}
+
+func TestBufferSizeSuggester(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		suggestion int
+		opts       []CopyOption
+		expected   int
+	}{
+		{name: "honors suggestion", suggestion: 4096, expected: 4096},
+		{name: "bounds suggestion", suggestion: 64 * 1024 * 1024, expected: 1024 * 1024},
+		{name: "ignores invalid suggestion", suggestion: -1, expected: 32 * 1024},
+		{name: "explicit buffer size wins", suggestion: 4096, opts: []CopyOption{BufferSize(16)}, expected: 16},
+		{name: "explicit buffer wins", suggestion: 4096, opts: []CopyOption{Buffer(make([]byte, 8))}, expected: 8},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			src := &suggestingReader{suggestion: tc.suggestion}
+
+			if _, err := Copy(context.Background(), io.Discard, src, tc.opts...); err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if !reflect.DeepEqual(src.readSizes, []int{tc.expected}) {
+				t.Fatalf("expected a single read of %d bytes but got %v", tc.expected, src.readSizes)
+			}
+		})
+	}
+}
+
+func TestResumableCopy(t *testing.T) {
+	const data = "0123456789abcdefghij"
+
+	var saved int64
+	var dst strings.Builder
+
+	// first run is interrupted by a failing destination after the second chunk
+	var writes int
+	_, err := Copy(
+		context.Background(),
+		WriterFunc(func(b []byte) (int, error) {
+			if writes++; writes > 2 {
+				return 0, errors.New("connection lost")
+			}
+			return dst.Write(b)
+		}),
+		strings.NewReader(data),
+		BufferSize(4),
+		SaveOffset(func(offset int64) error {
+			saved = offset
+			return nil
+		}),
+	)
+	if err == nil {
+		t.Fatal("expected first run to fail")
+	}
+	if saved != 8 {
+		t.Fatalf("expected saved offset to be 8 but got %d", saved)
+	}
+
+	for _, tc := range []struct {
+		name string
+		src  io.Reader
+	}{
+		{name: "seekable source", src: strings.NewReader(data)},
+		{name: "discarding source", src: ReaderFunc(strings.NewReader(data).Read)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := strings.Builder{}
+			out.WriteString(dst.String())
+
+			var offsets []int64
+			n, err := Copy(
+				context.Background(),
+				&out,
+				tc.src,
+				BufferSize(4),
+				LoadOffset(func() (int64, error) { return saved, nil }),
+				SaveOffset(func(offset int64) error {
+					offsets = append(offsets, offset)
+					return nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != 12 {
+				t.Fatalf("expected n to be 12 but got %d", n)
+			}
+			if out.String() != data {
+				t.Fatalf("expected resumed output to be %q but got %q", data, out.String())
+			}
+			if expected := []int64{12, 16, 20}; !reflect.DeepEqual(offsets, expected) {
+				t.Fatalf("expected saved offsets to be %v but got %v", expected, offsets)
+			}
+		})
+	}
+
+	t.Run("offset beyond source", func(t *testing.T) {
+		_, err := Copy(
+			context.Background(),
+			io.Discard,
+			ReaderFunc(strings.NewReader(data).Read),
+			LoadOffset(func() (int64, error) { return 100, nil }),
+		)
+		if err != io.ErrUnexpectedEOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrUnexpectedEOF, err)
+		}
+	})
+}
+
+func TestEnsureTrailingNewline(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "missing newline", input: "first\nsecond", expected: "first\nsecond\n"},
+		{name: "existing newline", input: "first\nsecond\n", expected: "first\nsecond\n"},
+		{name: "empty input", input: "", expected: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var dst strings.Builder
+
+			n, err := Copy(context.Background(), &dst, strings.NewReader(tc.input), BufferSize(4), EnsureTrailingNewline())
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if dst.String() != tc.expected {
+				t.Fatalf("expected %q but got %q", tc.expected, dst.String())
+			}
+			if n != int64(len(tc.expected)) {
+				t.Fatalf("expected n to be %d but got %d", len(tc.expected), n)
+			}
+		})
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		input    string
+		max      int64
+		expected string
+		err      error
+	}{
+		{name: "under the limit", input: "hello", max: 10, expected: "hello"},
+		{name: "at the limit", input: "hello", max: 5, expected: "hello"},
+		{name: "over the limit", input: "hello world", max: 5, expected: "hello", err: ErrMaxBytesExceeded},
+		{name: "no limit", input: "hello world", max: 0, expected: "hello world"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var dst strings.Builder
+
+			n, err := Copy(context.Background(), &dst, strings.NewReader(tc.input), BufferSize(3), MaxBytes(tc.max))
+			if err != tc.err {
+				t.Fatalf("expected err to be %#q but got %#q", tc.err, err)
+			}
+			if dst.String() != tc.expected || n != int64(len(tc.expected)) {
+				t.Fatalf("expected %q to be copied but got %d bytes: %q", tc.expected, n, dst.String())
+			}
+		})
+	}
+}
+
+func TestPredictSize(t *testing.T) {
+	type call struct {
+		sofar int64
+		rate  float64
+	}
+
+	var calls []call
+	var progress [][2]int64
+
+	n, err := Copy(
+		context.Background(),
+		io.Discard,
+		strings.NewReader(strings.Repeat("x", 100)),
+		BufferSize(30),
+		PredictSize(func(sofar int64, rate float64) int64 {
+			calls = append(calls, call{sofar, rate})
+			return sofar * 2
+		}),
+		Progress(func(written, total int64) {
+			progress = append(progress, [2]int64{written, total})
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+	if n != 100 {
+		t.Fatalf("expected n to be 100 but got %d", n)
+	}
+
+	if len(calls) != 4 {
+		t.Fatalf("expected the predictor to be called once per chunk but got %d calls", len(calls))
+	}
+	for i, c := range calls {
+		if c.rate <= 0 {
+			t.Fatalf("expected a positive rate but got %v", c.rate)
+		}
+		if i > 0 && c.sofar <= calls[i-1].sofar {
+			t.Fatalf("expected sofar to increase but got %v", calls)
+		}
+	}
+
+	expected := [][2]int64{{30, 60}, {60, 120}, {90, 180}, {100, 200}}
+	if !reflect.DeepEqual(progress, expected) {
+		t.Fatalf("expected progress to be %v but got %v", expected, progress)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var progress [][2]int64
+
+	_, err := Copy(
+		context.Background(),
+		io.Discard,
+		strings.NewReader("0123456789"),
+		BufferSize(4),
+		Progress(func(written, total int64) {
+			progress = append(progress, [2]int64{written, total})
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+
+	expected := [][2]int64{{4, -1}, {8, -1}, {10, -1}}
+	if !reflect.DeepEqual(progress, expected) {
+		t.Fatalf("expected progress to be %v but got %v", expected, progress)
+	}
+}
+
+func TestAllowEmptyFlush(t *testing.T) {
+	newSrc := func() io.Reader {
+		reads := []string{"", "abc", "", "def"}
+		return ReaderFunc(func(b []byte) (int, error) {
+			if len(reads) == 0 {
+				return 0, io.EOF
+			}
+			n := copy(b, reads[0])
+			reads = reads[1:]
+			return n, nil
+		})
+	}
+
+	for _, tc := range []struct {
+		name     string
+		opts     []CopyOption
+		expected []string
+	}{
+		{name: "forwards empty reads after data", opts: []CopyOption{AllowEmptyFlush()}, expected: []string{"abc", "", "def"}},
+		{name: "skips empty reads by default", expected: []string{"abc", "def"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var writes []string
+
+			n, err := Copy(
+				context.Background(),
+				WriterFunc(func(b []byte) (int, error) {
+					writes = append(writes, string(b))
+					return len(b), nil
+				}),
+				newSrc(),
+				tc.opts...,
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != 6 {
+				t.Fatalf("expected n to be 6 but got %d", n)
+			}
+			if !reflect.DeepEqual(writes, tc.expected) {
+				t.Fatalf("expected writes to be %q but got %q", tc.expected, writes)
+			}
+		})
+	}
+}
+
+func TestOnComplete(t *testing.T) {
+	type completion struct {
+		n   int64
+		err error
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var calls []completion
+		n, err := Copy(
+			context.Background(),
+			&bytes.Buffer{},
+			strings.NewReader("hello world"),
+			OnComplete(func(n int64, err error) { calls = append(calls, completion{n, err}) }),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if expected := []completion{{n: n}}; !reflect.DeepEqual(calls, expected) {
+			t.Fatalf("expected hook to be called with %v but got %v", expected, calls)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		readErr := errors.New("reader broke!")
+
+		var calls []completion
+		_, err := Copy(
+			context.Background(),
+			&bytes.Buffer{},
+			ReaderFunc(func(b []byte) (int, error) { return 42, readErr }),
+			OnComplete(func(n int64, err error) { calls = append(calls, completion{n, err}) }),
+		)
+		if err != readErr {
+			t.Fatalf("expected err to be %#q but got %#q", readErr, err)
+		}
+		if expected := []completion{{n: 42, err: readErr}}; !reflect.DeepEqual(calls, expected) {
+			t.Fatalf("expected hook to be called with %v but got %v", expected, calls)
+		}
+	})
+
+	t.Run("canceled before starting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var calls []completion
+		Copy(ctx, nil, nil, OnComplete(func(n int64, err error) { calls = append(calls, completion{n, err}) }))
+
+		if expected := []completion{{err: context.Canceled}}; !reflect.DeepEqual(calls, expected) {
+			t.Fatalf("expected hook to be called with %v but got %v", expected, calls)
+		}
+	})
+
+	t.Run("canceled without waiting for last op", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		unblockWrite := make(chan struct{})
+		completions := make(chan completion, 2)
+
+		n, err := Copy(
+			ctx,
+			WriterFunc(func(b []byte) (int, error) {
+				cancel()
+				<-unblockWrite
+				return len(b), nil
+			}),
+			ReaderFunc(func(b []byte) (int, error) { return len(b), nil }),
+			WaitForLastOp(false),
+			BufferSize(16),
+			OnComplete(func(n int64, err error) { completions <- completion{n, err} }),
+		)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected error to be context canceled but got %v", err)
+		}
+		if n != 0 {
+			t.Fatalf("expected n to be 0 but got %d", n)
+		}
+
+		select {
+		case c := <-completions:
+			t.Fatalf("expected hook to wait for the copy goroutine but it was called with %v", c)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		close(unblockWrite)
+
+		if c := <-completions; c.n != 16 || c.err != context.Canceled {
+			t.Fatalf("expected hook to be called with the final count 16 and %#q but got %v", context.Canceled, c)
+		}
+
+		select {
+		case c := <-completions:
+			t.Fatalf("expected hook to be called once but it was called again with %v", c)
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+}
+
+func TestHMAC(t *testing.T) {
+	key := []byte("secret")
+	data := strings.Repeat("authenticated data ", 100)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	expected := mac.Sum(nil)
+
+	t.Run("reports the MAC", func(t *testing.T) {
+		var actual []byte
+		_, err := Copy(
+			context.Background(),
+			&bytes.Buffer{},
+			strings.NewReader(data),
+			BufferSize(64),
+			HMAC(key, sha256.New, func(mac []byte) { actual = mac }),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if !hmac.Equal(actual, expected) {
+			t.Fatalf("expected MAC to be %x but got %x", expected, actual)
+		}
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		_, err := Copy(context.Background(), &bytes.Buffer{}, strings.NewReader(data), VerifyHMAC(key, sha256.New, expected))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		_, err = Copy(context.Background(), &bytes.Buffer{}, strings.NewReader(data+"!"), VerifyHMAC(key, sha256.New, expected))
+		if err != ErrMACMismatch {
+			t.Fatalf("expected err to be %#q but got %#q", ErrMACMismatch, err)
+		}
+	})
+}
+
+func TestOnBufferFill(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		src      io.Reader
+		expected []float64
+	}{
+		{name: "full reads", src: strings.NewReader(strings.Repeat("x", 32)), expected: []float64{1, 1}},
+		{name: "partial reads", src: iotest.HalfReader(strings.NewReader(strings.Repeat("x", 24))), expected: []float64{0.5, 0.5, 0.5}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ratios []float64
+			_, err := Copy(
+				context.Background(),
+				&bytes.Buffer{},
+				tc.src,
+				BufferSize(16),
+				OnBufferFill(func(fillRatio float64) { ratios = append(ratios, fillRatio) }),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if !reflect.DeepEqual(ratios, tc.expected) {
+				t.Fatalf("expected fill ratios to be %v but got %v", tc.expected, ratios)
+			}
+		})
+	}
+}
+
+func TestManifest(t *testing.T) {
+	var entries []ManifestEntry
+
+	n, err := Copy(
+		context.Background(),
+		&bytes.Buffer{},
+		iotest.HalfReader(strings.NewReader(strings.Repeat("x", 50))),
+		BufferSize(16),
+		Manifest(func(e []ManifestEntry) { entries = e }),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+
+	expected := []ManifestEntry{{0, 8}, {8, 8}, {16, 8}, {24, 8}, {32, 8}, {40, 8}, {48, 2}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("expected entries to be %v but got %v", expected, entries)
+	}
+
+	var total int64
+	for _, entry := range entries {
+		if entry.Offset != total {
+			t.Fatalf("expected entry to start at %d but got %d", total, entry.Offset)
+		}
+		total += entry.Length
+	}
+	if total != n {
+		t.Fatalf("expected lengths to sum to %d but got %d", n, total)
+	}
+}
+
+func TestFlushOnIdle(t *testing.T) {
+	burstyReader := func(reads ...string) io.Reader {
+		return ReaderFunc(func(b []byte) (int, error) {
+			if len(reads) == 0 {
+				return 0, io.EOF
+			}
+			read := reads[0]
+			reads = reads[1:]
+			if read == "" {
+				time.Sleep(50 * time.Millisecond)
+				return 0, nil
+			}
+			return copy(b, read), nil
+		})
+	}
+
+	t.Run("flushes during pauses", func(t *testing.T) {
+		dst := &flushWriter{}
+
+		_, err := Copy(context.Background(), dst, burstyReader("a", "b", "", "c", "d", "", ""), FlushOnIdle(20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		expected := []string{"write:a", "write:b", "flush", "write:c", "write:d", "flush"}
+		if !reflect.DeepEqual(dst.events, expected) {
+			t.Fatalf("expected events to be %v but got %v", expected, dst.events)
+		}
+	})
+
+	t.Run("no flush without pauses", func(t *testing.T) {
+		dst := &flushWriter{}
+
+		_, err := Copy(context.Background(), dst, burstyReader("a", "b", "c"), FlushOnIdle(20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		expected := []string{"write:a", "write:b", "write:c"}
+		if !reflect.DeepEqual(dst.events, expected) {
+			t.Fatalf("expected events to be %v but got %v", expected, dst.events)
+		}
+	})
+
+	t.Run("flush error aborts", func(t *testing.T) {
+		flushErr := errors.New("client gone")
+		dst := &flushWriter{flushErr: flushErr}
+
+		_, err := Copy(context.Background(), dst, burstyReader("a", "", "b"), FlushOnIdle(20*time.Millisecond))
+		if err != flushErr {
+			t.Fatalf("expected err to be %#q but got %#q", flushErr, err)
+		}
+	})
+}
+
+func TestSlowestOp(t *testing.T) {
+	reads := []time.Duration{0, 30 * time.Millisecond, 0}
+	writes := []time.Duration{10 * time.Millisecond, 0, 0}
+
+	slowest := map[string]time.Duration{}
+
+	_, err := Copy(
+		context.Background(),
+		WriterFunc(func(b []byte) (int, error) {
+			time.Sleep(writes[0])
+			writes = writes[1:]
+			return len(b), nil
+		}),
+		ReaderFunc(func(b []byte) (int, error) {
+			time.Sleep(reads[0])
+			reads = reads[1:]
+			if len(reads) == 0 {
+				return 1, io.EOF
+			}
+			return 1, nil
+		}),
+		SlowestOp(func(kind string, d time.Duration) { slowest[kind] = d }),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+
+	if d := slowest["read"]; d < 30*time.Millisecond || d > 45*time.Millisecond {
+		t.Fatalf("expected slowest read to take around 30ms but got %v", d)
+	}
+	if d := slowest["write"]; d < 10*time.Millisecond || d > 25*time.Millisecond {
+		t.Fatalf("expected slowest write to take around 10ms but got %v", d)
+	}
+}
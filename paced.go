@@ -0,0 +1,45 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// PacedReader returns a reader that paces reads from r so that totalBytes are read around finishBy, neither faster nor
+// slower if r keeps up. After every Read it waits for the share of the remaining time matching the share of the
+// remaining bytes just read, so the rate adjusts as it goes to delays on the side of r or of the consumer. Reads are
+// not delayed once finishBy has passed or totalBytes have been read. If the context is canceled while waiting, the
+// bytes read are returned along with the context error.
+func PacedReader(ctx context.Context, r io.Reader, totalBytes int64, finishBy time.Time) io.Reader {
+	return &pacedReader{ctx: ctx, r: r, total: totalBytes, finishBy: finishBy}
+}
+
+type pacedReader struct {
+	ctx      context.Context
+	r        io.Reader
+	total    int64
+	read     int64
+	finishBy time.Time
+}
+
+func (pr *pacedReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	remaining := pr.total - pr.read
+	pr.read += int64(n)
+
+	if remaining > 0 {
+		share := float64(n) / float64(remaining)
+		if share > 1 {
+			share = 1
+		}
+		if sleepErr := sleep(pr.ctx, time.Duration(share*float64(time.Until(pr.finishBy)))); sleepErr != nil {
+			return n, sleepErr
+		}
+	}
+	return n, err
+}
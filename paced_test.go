@@ -0,0 +1,76 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPacedReader(t *testing.T) {
+	t.Run("completes around the target", func(t *testing.T) {
+		const duration = 100 * time.Millisecond
+
+		start := time.Now()
+		var offsets []time.Duration
+
+		n, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				offsets = append(offsets, time.Since(start))
+				return len(b), nil
+			}),
+			PacedReader(context.Background(), bytes.NewReader(make([]byte, 100)), 100, start.Add(duration)),
+			BufferSize(25),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 100 {
+			t.Fatalf("expected n to be 100 but got %d", n)
+		}
+
+		if elapsed := time.Since(start); elapsed < duration || elapsed > duration+duration/2 {
+			t.Fatalf("expected copy to complete around %v but took %v", duration, elapsed)
+		}
+
+		// chunks are evenly spread over the duration
+		for i, offset := range offsets {
+			if expected := time.Duration(i+1) * duration / 4; offset < expected || offset > expected+duration/4 {
+				t.Fatalf("expected chunk %d to be written around %v but was at %v", i, expected, offset)
+			}
+		}
+	})
+
+	t.Run("does not wait past the target", func(t *testing.T) {
+		start := time.Now()
+
+		_, err := Copy(
+			context.Background(),
+			&bytes.Buffer{},
+			PacedReader(context.Background(), bytes.NewReader(make([]byte, 100)), 10, start.Add(-time.Second)),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Fatalf("expected copy to complete immediately but took %v", elapsed)
+		}
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		r := PacedReader(ctx, bytes.NewReader(make([]byte, 100)), 100, time.Now().Add(time.Hour))
+
+		n, err := r.Read(make([]byte, 10))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be context deadline exceeded but got %v", err)
+		}
+		if n != 10 {
+			t.Fatalf("expected the bytes read to be returned but got %d", n)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package xio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// ErrPanic is matched by the error returned when the RecoverPanics option catches a panic during a copy.
+var ErrPanic = errors.New("panic during copy")
+
+// PanicError holds a panic recovered during a copy, along with the stack of the goroutine that panicked.
+// It matches ErrPanic with errors.Is, and unwraps to the recovered value when that value is an error.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("%v: %v", ErrPanic, e.Value) }
+
+func (e *PanicError) Is(target error) bool { return target == ErrPanic }
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// catchPanic calls fn, turning a panic into a *PanicError.
+func catchPanic(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// panicSafeReader turns panics from r into a *PanicError, for reads made outside of the copy goroutine.
+type panicSafeReader struct {
+	r io.Reader
+}
+
+func (pr panicSafeReader) Read(p []byte) (n int, err error) {
+	err = catchPanic(func() error {
+		n, err = pr.r.Read(p)
+		return err
+	})
+	return n, err
+}
@@ -0,0 +1,68 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRecoverPanics(t *testing.T) {
+	t.Run("reader panic", func(t *testing.T) {
+		_, err := Copy(context.Background(), io.Discard, ReaderFunc(func(b []byte) (int, error) {
+			panic("reader exploded")
+		}), RecoverPanics())
+
+		if !errors.Is(err, ErrPanic) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrPanic, err)
+		}
+
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("expected a panic error but got %T", err)
+		}
+		if panicErr.Value != "reader exploded" {
+			t.Fatalf("expected recovered value to be %q but got %v", "reader exploded", panicErr.Value)
+		}
+		if len(panicErr.Stack) == 0 {
+			t.Fatalf("expected stack to be captured")
+		}
+	})
+
+	t.Run("writer panic with error value", func(t *testing.T) {
+		cause := errors.New("writer exploded")
+
+		n, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+			panic(cause)
+		}), strings.NewReader("hello"), RecoverPanics())
+
+		if !errors.Is(err, ErrPanic) || !errors.Is(err, cause) {
+			t.Fatalf("expected err to match both %#q and %#q but got %#q", ErrPanic, cause, err)
+		}
+		if n != 0 {
+			t.Fatalf("expected no bytes to be copied but got %d", n)
+		}
+	})
+
+	t.Run("reader panic with read ahead", func(t *testing.T) {
+		_, err := Copy(context.Background(), io.Discard, ReaderFunc(func(b []byte) (int, error) {
+			panic("reader exploded")
+		}), ReadAhead(2), RecoverPanics())
+
+		if !errors.Is(err, ErrPanic) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrPanic, err)
+		}
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		var dst strings.Builder
+		n, err := Copy(context.Background(), &dst, strings.NewReader("hello"), RecoverPanics())
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 5 || dst.String() != "hello" {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+}
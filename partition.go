@@ -0,0 +1,48 @@
+package xio
+
+import "io"
+
+// ErrPartition is returned by a PartitionReader while a failure window is active. It reports itself as temporary.
+var ErrPartition error = temporaryError("simulated network partition")
+
+type temporaryError string
+
+func (e temporaryError) Error() string   { return string(e) }
+func (e temporaryError) Temporary() bool { return true }
+
+// PartitionReader returns a reader that simulates network partitions for chaos testing. For each failure window,
+// once After bytes have been read from r, the next For reads fail with ErrPartition, after which the reader recovers
+// and continues from where it left off. Reads are shortened so that they never cross the start of a window.
+// Windows are expected to be sorted by After.
+func PartitionReader(r io.Reader, failWindows []struct{ After, For int64 }) io.Reader {
+	return &partitionReader{r: r, windows: failWindows}
+}
+
+type partitionReader struct {
+	r       io.Reader
+	windows []struct{ After, For int64 }
+	offset  int64
+	failed  int64
+}
+
+func (r *partitionReader) Read(p []byte) (int, error) {
+	for len(r.windows) > 0 {
+		window := r.windows[0]
+		if r.offset < window.After {
+			if remaining := window.After - r.offset; int64(len(p)) > remaining {
+				p = p[:remaining]
+			}
+			break
+		}
+		if r.failed < window.For {
+			r.failed++
+			return 0, ErrPartition
+		}
+		r.windows = r.windows[1:]
+		r.failed = 0
+	}
+
+	n, err := r.r.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
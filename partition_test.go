@@ -0,0 +1,56 @@
+package xio
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPartitionReader(t *testing.T) {
+	r := PartitionReader(
+		strings.NewReader("0123456789"),
+		[]struct{ After, For int64 }{
+			{After: 3, For: 2},
+			{After: 7, For: 1},
+		},
+	)
+
+	type result struct {
+		data string
+		err  error
+	}
+
+	var results []result
+	buf := make([]byte, 4)
+	for {
+		n, err := r.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		results = append(results, result{data: string(buf[:n]), err: err})
+	}
+
+	expected := []result{
+		{data: "012"},
+		{err: ErrPartition},
+		{err: ErrPartition},
+		{data: "3456"},
+		{err: ErrPartition},
+		{data: "789"},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d reads but got %d: %v", len(expected), len(results), results)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Fatalf("expected read %d to be %v but got %v", i, expected[i], results[i])
+		}
+	}
+
+	var temporary interface{ Temporary() bool }
+	if !errors.As(ErrPartition, &temporary) || !temporary.Temporary() {
+		t.Fatal("expected ErrPartition to be temporary")
+	}
+}
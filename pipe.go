@@ -0,0 +1,187 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// BufferedPipe creates an in-memory pipe like io.Pipe, except that it buffers up to capacity bytes: writes only block
+// once the buffer is full rather than until a reader takes the data, so the producer and the consumer don't have to
+// run in lockstep. Data is read in the order it was written. Canceling the context unblocks both ends, which then fail
+// with the context error, discarding the buffered data. Both ends should be closed once done with, to release the
+// goroutine watching the context. A capacity below 1 is treated as 1.
+func BufferedPipe(ctx context.Context, capacity int) (*PipeReader, *PipeWriter) {
+	if capacity < 1 {
+		capacity = 1
+	}
+	p := &bufferedPipe{buf: make([]byte, capacity), done: make(chan struct{})}
+	p.cond = sync.NewCond(&p.mu)
+
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				p.mu.Lock()
+				p.ctxErr = ctx.Err()
+				p.cond.Broadcast()
+				p.mu.Unlock()
+			case <-p.done:
+			}
+		}()
+	}
+
+	return &PipeReader{p: p}, &PipeWriter{p: p}
+}
+
+// PipeReader is the read half of a BufferedPipe.
+type PipeReader struct {
+	p *bufferedPipe
+}
+
+// Read reads buffered data, waiting for some to be written if the buffer is empty. Once the writer is closed and the
+// buffer drained it returns io.EOF, or the error the writer was closed with.
+func (r *PipeReader) Read(p []byte) (int, error) { return r.p.read(p) }
+
+// Close closes the reader: subsequent writes fail with io.ErrClosedPipe.
+func (r *PipeReader) Close() error { return r.CloseWithError(nil) }
+
+// CloseWithError closes the reader: subsequent writes fail with err, or io.ErrClosedPipe if err is nil.
+func (r *PipeReader) CloseWithError(err error) error {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	r.p.closeRead(err)
+	return nil
+}
+
+// PipeWriter is the write half of a BufferedPipe.
+type PipeWriter struct {
+	p *bufferedPipe
+}
+
+// Write copies p into the buffer, waiting for the reader to make room whenever it is full. It returns the number of
+// bytes buffered, and an error if the pipe was closed or the context canceled before all of p could be.
+func (w *PipeWriter) Write(p []byte) (int, error) { return w.p.write(p) }
+
+// Close closes the writer: the reader returns io.EOF once it has read the buffered data.
+func (w *PipeWriter) Close() error { return w.CloseWithError(nil) }
+
+// CloseWithError closes the writer: the reader returns err, or io.EOF if err is nil, once it has read the buffered
+// data.
+func (w *PipeWriter) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	w.p.closeWrite(err)
+	return nil
+}
+
+// bufferedPipe is a ring buffer shared by the two ends of a BufferedPipe.
+type bufferedPipe struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	buf   []byte
+	start int
+	size  int
+
+	// rerr is set once the reader is closed, werr once the writer is, and ctxErr once the context is canceled.
+	rerr   error
+	werr   error
+	ctxErr error
+
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (p *bufferedPipe) read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.size == 0 && p.werr == nil && p.rerr == nil && p.ctxErr == nil {
+		p.cond.Wait()
+	}
+	switch {
+	case p.ctxErr != nil:
+		return 0, p.ctxErr
+	case p.rerr != nil:
+		return 0, io.ErrClosedPipe
+	case p.size == 0:
+		return 0, p.werr
+	}
+
+	var n int
+	for n < len(b) && p.size > 0 {
+		end := p.start + p.size
+		if end > len(p.buf) {
+			end = len(p.buf)
+		}
+		m := copy(b[n:], p.buf[p.start:end])
+		n += m
+		p.start = (p.start + m) % len(p.buf)
+		p.size -= m
+	}
+	p.cond.Broadcast()
+	return n, nil
+}
+
+func (p *bufferedPipe) write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var n int
+	for {
+		switch {
+		case p.ctxErr != nil:
+			return n, p.ctxErr
+		case p.werr != nil:
+			return n, io.ErrClosedPipe
+		case p.rerr != nil:
+			return n, p.rerr
+		case n == len(b):
+			return n, nil
+		case p.size == len(p.buf):
+			p.cond.Wait()
+			continue
+		}
+
+		end := (p.start + p.size) % len(p.buf)
+		limit := len(p.buf)
+		if end < p.start {
+			limit = p.start
+		}
+		m := copy(p.buf[end:limit], b[n:])
+		n += m
+		p.size += m
+		p.cond.Broadcast()
+	}
+}
+
+func (p *bufferedPipe) closeRead(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rerr == nil {
+		p.rerr = err
+		p.cond.Broadcast()
+	}
+	p.release()
+}
+
+func (p *bufferedPipe) closeWrite(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.werr == nil {
+		p.werr = err
+		p.cond.Broadcast()
+	}
+	p.release()
+}
+
+// release stops watching the context once both ends are closed.
+func (p *bufferedPipe) release() {
+	if p.rerr != nil && p.werr != nil {
+		p.doneOnce.Do(func() { close(p.done) })
+	}
+}
@@ -0,0 +1,110 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestBufferedPipe(t *testing.T) {
+	t.Run("write within capacity", func(t *testing.T) {
+		r, w := BufferedPipe(context.Background(), 16)
+		defer r.Close()
+
+		// nobody is reading yet, the writes must not block
+		for _, chunk := range []string{"hello ", "world"} {
+			if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+				t.Fatalf("expected %d bytes written and no error but got %d and %#q", len(chunk), n, err)
+			}
+		}
+		w.Close()
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(data) != "hello world" {
+			t.Fatalf("expected %q but got %q", "hello world", data)
+		}
+	})
+
+	t.Run("write beyond capacity", func(t *testing.T) {
+		r, w := BufferedPipe(context.Background(), 7)
+		defer r.Close()
+
+		src := make([]byte, 1000)
+		for i := range src {
+			src[i] = byte(i)
+		}
+
+		go func() {
+			_, err := Copy(context.Background(), w, bytes.NewReader(src), BufferSize(13))
+			w.CloseWithError(err)
+		}()
+
+		var dst bytes.Buffer
+		if _, err := Copy(context.Background(), &dst, r, BufferSize(5)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if !bytes.Equal(dst.Bytes(), src) {
+			t.Fatalf("expected data to be read in order")
+		}
+	})
+
+	t.Run("closed reader", func(t *testing.T) {
+		r, w := BufferedPipe(context.Background(), 4)
+		defer w.Close()
+
+		readErr := errors.New("consumer gone")
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			r.CloseWithError(readErr)
+		}()
+
+		n, err := w.Write([]byte("too much data"))
+		if !errors.Is(err, readErr) {
+			t.Fatalf("expected err to be %#q but got %#q", readErr, err)
+		}
+		if n != 4 {
+			t.Fatalf("expected the capacity to be written but got %d", n)
+		}
+	})
+
+	t.Run("cancelation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		r, w := BufferedPipe(ctx, 4)
+		defer r.Close()
+		defer w.Close()
+
+		writeErr := make(chan error)
+		go func() {
+			_, err := w.Write([]byte("beyond capacity"))
+			writeErr <- err
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		if err := <-writeErr; !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected err to be %#q but got %#q", context.Canceled, err)
+		}
+		if _, err := r.Read(make([]byte, 4)); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected err to be %#q but got %#q", context.Canceled, err)
+		}
+	})
+
+	t.Run("cancelation unblocks reader", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		r, w := BufferedPipe(ctx, 4)
+		defer r.Close()
+		defer w.Close()
+
+		if _, err := r.Read(make([]byte, 4)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+	})
+}
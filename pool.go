@@ -0,0 +1,36 @@
+package xio
+
+import (
+	"context"
+	"sync"
+)
+
+// bufferFromContext gets a buffer from the *sync.Pool stored in the context under key. It returns a nil buffer if there
+// is none. release puts the buffer back into the pool, unless keep is true.
+func bufferFromContext(ctx context.Context, key any, keep bool) (buf []byte, release func()) {
+	release = func() {}
+
+	pool, ok := ctx.Value(key).(*sync.Pool)
+	if !ok {
+		return nil, release
+	}
+
+	switch v := pool.Get().(type) {
+	case []byte:
+		buf = v
+		if !keep && len(v) > 0 {
+			release = func() { pool.Put(v) }
+		}
+	case *[]byte:
+		if v != nil {
+			buf = *v
+			if !keep && len(buf) > 0 {
+				release = func() { pool.Put(v) }
+			}
+		}
+	}
+	if len(buf) == 0 {
+		return nil, func() {}
+	}
+	return buf, release
+}
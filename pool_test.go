@@ -0,0 +1,72 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type poolKey struct{}
+
+func TestBufferFromContext(t *testing.T) {
+	t.Run("buffer is reused", func(t *testing.T) {
+		var allocations int
+		pool := &sync.Pool{New: func() any {
+			allocations++
+			b := make([]byte, 8)
+			return &b
+		}}
+		ctx := context.WithValue(context.Background(), poolKey{}, pool)
+
+		var used *byte
+		var dst bytes.Buffer
+		n, err := Copy(
+			ctx,
+			WriterFunc(func(b []byte) (int, error) {
+				if len(b) > 8 {
+					t.Errorf("expected chunks of at most the pooled buffer size but got %d", len(b))
+				}
+				used = &b[0]
+				return dst.Write(b)
+			}),
+			strings.NewReader("request scoped"),
+			BufferFromContext(poolKey{}),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 14 || dst.String() != "request scoped" {
+			t.Fatalf("expected data to be copied but got %d bytes: %q", n, dst.String())
+		}
+
+		b := pool.Get().(*[]byte)
+		// sync.Pool may drop items at any time, in particular under the race detector.
+		if allocations > 1 {
+			t.Skip("pool dropped the buffer")
+		}
+		if &(*b)[0] != used {
+			t.Fatal("expected the buffer to be put back into the pool")
+		}
+	})
+
+	t.Run("falls back without a pool", func(t *testing.T) {
+		var sizes []int
+		_, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				sizes = append(sizes, len(b))
+				return len(b), nil
+			}),
+			strings.NewReader(strings.Repeat("x", 100)),
+			BufferFromContext(poolKey{}),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if len(sizes) != 1 || sizes[0] != 100 {
+			t.Fatalf("expected a single chunk using the default buffer but got %v", sizes)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package xio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// progressFile periodically writes the number of bytes copied so far to a file, for external monitoring.
+type progressFile struct {
+	path  string
+	total int64
+	n     *atomic.Int64
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// startProgressFile writes the progress to path every interval until close is called. total is -1 when unknown.
+// A non-positive interval only writes the progress when starting and closing.
+func startProgressFile(path string, every time.Duration, total int64, n *atomic.Int64) *progressFile {
+	pf := &progressFile{
+		path:  path,
+		total: total,
+		n:     n,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	pf.write()
+
+	if every <= 0 {
+		close(pf.done)
+		return pf
+	}
+
+	go func() {
+		defer close(pf.done)
+
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				pf.write()
+			case <-pf.stop:
+				return
+			}
+		}
+	}()
+
+	return pf
+}
+
+// close stops the periodic updates and writes the final count.
+func (pf *progressFile) close() {
+	close(pf.stop)
+	<-pf.done
+	pf.write()
+}
+
+// write replaces the file with the current progress, through a rename so that readers never see a partial update.
+// Monitoring must not fail the copy, so errors are ignored and the next update tries again.
+func (pf *progressFile) write() {
+	written := pf.n.Load()
+
+	content := fmt.Sprintf("written=%d\n", written)
+	if pf.total >= 0 {
+		percent := float64(100)
+		if pf.total > 0 {
+			percent = 100 * float64(written) / float64(pf.total)
+		}
+		content += fmt.Sprintf("total=%d\npercent=%.1f\n", pf.total, percent)
+	}
+
+	tmp := pf.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o666); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, pf.path); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// knownSize returns the number of bytes left in src when it can tell, or -1.
+func knownSize(src io.Reader) int64 {
+	switch r := src.(type) {
+	case *io.LimitedReader:
+		if size := knownSize(r.R); size >= 0 && size < r.N {
+			return size
+		}
+		return r.N
+	case interface{ Len() int }:
+		return int64(r.Len())
+	}
+	return -1
+}
@@ -0,0 +1,87 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressFile(t *testing.T) {
+	t.Run("updates over time", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "progress")
+
+		var snapshots []string
+		var reads int
+		src := ReaderFunc(func(b []byte) (int, error) {
+			if reads == 3 {
+				return 0, io.EOF
+			}
+			reads++
+			// give the progress file time to catch up with the previous chunk
+			time.Sleep(30 * time.Millisecond)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Errorf("expected err to be nil but got %#q", err)
+			}
+			snapshots = append(snapshots, string(data))
+			return copy(b, "0123456789"), nil
+		})
+
+		n, err := Copy(context.Background(), io.Discard, src, ProgressFile(path, 5*time.Millisecond))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 30 {
+			t.Fatalf("expected n to be 30 but got %d", n)
+		}
+
+		expected := []string{"written=0\n", "written=10\n", "written=20\n"}
+		if strings.Join(snapshots, "|") != strings.Join(expected, "|") {
+			t.Fatalf("expected snapshots %q but got %q", expected, snapshots)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(data) != "written=30\n" {
+			t.Fatalf("expected final count to be written but got %q", data)
+		}
+		if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+			t.Fatalf("expected temporary file to be renamed but got %#q", err)
+		}
+	})
+
+	t.Run("known total", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "progress")
+
+		var snapshot string
+		w := WriterFunc(func(b []byte) (int, error) {
+			time.Sleep(30 * time.Millisecond)
+			if data, err := os.ReadFile(path); err == nil && snapshot == "" {
+				snapshot = string(data)
+			}
+			return len(b), nil
+		})
+
+		if _, err := Copy(context.Background(), w, bytes.NewReader(make([]byte, 40)), BufferSize(10), ProgressFile(path, 5*time.Millisecond)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if snapshot != "written=0\ntotal=40\npercent=0.0\n" {
+			t.Fatalf("expected first snapshot to hold the total but got %q", snapshot)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(data) != "written=40\ntotal=40\npercent=100.0\n" {
+			t.Fatalf("expected completion to be written but got %q", data)
+		}
+	})
+}
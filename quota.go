@@ -0,0 +1,99 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// QuotaReader returns a reader allowing up to bytesPerInterval bytes to be read from r per fixed window of interval.
+// Windows start with the first Read. Once the quota of the current window is exhausted, Read blocks until the next
+// window begins, replenishing the quota, or until the context is canceled in which case the context error is returned.
+// Unlike a rate limit, the quota may be consumed in a single burst at the start of every window.
+func QuotaReader(ctx context.Context, r io.Reader, bytesPerInterval int64, interval time.Duration) io.Reader {
+	return &quotaReader{ctx: ctx, r: r, quota: bytesPerInterval, interval: interval}
+}
+
+type quotaReader struct {
+	ctx      context.Context
+	r        io.Reader
+	quota    int64
+	interval time.Duration
+	window   time.Time
+	used     int64
+}
+
+func (qr *quotaReader) Read(p []byte) (int, error) {
+	if qr.window.IsZero() {
+		qr.window = time.Now()
+	}
+
+	for {
+		if elapsed := time.Since(qr.window); elapsed >= qr.interval {
+			qr.window = qr.window.Add(elapsed.Truncate(qr.interval))
+			qr.used = 0
+		}
+		if qr.used < qr.quota {
+			break
+		}
+		if err := sleepUntil(qr.ctx, qr.window.Add(qr.interval)); err != nil {
+			return 0, err
+		}
+	}
+
+	if remaining := qr.quota - qr.used; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := qr.r.Read(p)
+	qr.used += int64(n)
+	return n, err
+}
+
+// ErrQuotaExceeded is returned by a RollingQuotaReader when its quota is exhausted.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// RollingQuotaReader returns a reader allowing up to bytesPerWindow bytes to be read from r within any rolling window
+// of the given duration, for enforcing per-client data quotas. Reads are shortened to the remaining quota, and once it
+// is exhausted Read fails with ErrQuotaExceeded, without blocking, until enough of the bytes read have left the
+// window. Unlike QuotaReader, which waits for fixed windows, the caller decides how to handle an exceeded quota.
+func RollingQuotaReader(r io.Reader, bytesPerWindow int64, window time.Duration) io.Reader {
+	return &rollingQuotaReader{r: r, quota: bytesPerWindow, window: window}
+}
+
+type rollingQuotaReader struct {
+	r      io.Reader
+	quota  int64
+	window time.Duration
+	reads  []quotaRead
+	used   int64
+}
+
+// quotaRead records the number of bytes read at a given time.
+type quotaRead struct {
+	at time.Time
+	n  int64
+}
+
+func (qr *rollingQuotaReader) Read(p []byte) (int, error) {
+	now := time.Now()
+	for len(qr.reads) > 0 && now.Sub(qr.reads[0].at) >= qr.window {
+		qr.used -= qr.reads[0].n
+		qr.reads = qr.reads[1:]
+	}
+
+	remaining := qr.quota - qr.used
+	if remaining <= 0 {
+		return 0, ErrQuotaExceeded
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := qr.r.Read(p)
+	if n > 0 {
+		qr.reads = append(qr.reads, quotaRead{at: now, n: int64(n)})
+		qr.used += int64(n)
+	}
+	return n, err
+}
@@ -0,0 +1,111 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuotaReader(t *testing.T) {
+	t.Run("window boundaries reset the quota", func(t *testing.T) {
+		const interval = 50 * time.Millisecond
+
+		begin := time.Now()
+		var offsets []time.Duration
+		var sizes []int
+
+		_, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				offsets = append(offsets, time.Since(begin))
+				sizes = append(sizes, len(b))
+				return len(b), nil
+			}),
+			QuotaReader(context.Background(), bytes.NewReader(make([]byte, 25)), 10, interval),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		expectedSizes := []int{10, 10, 5}
+		if len(sizes) != len(expectedSizes) {
+			t.Fatalf("expected reads of %v but got %v", expectedSizes, sizes)
+		}
+		for i, size := range sizes {
+			if size != expectedSizes[i] {
+				t.Fatalf("expected reads of %v but got %v", expectedSizes, sizes)
+			}
+			if window := time.Duration(i) * interval; offsets[i] < window || offsets[i] > window+interval/2 {
+				t.Fatalf("expected read %d to happen at the start of window %v but happened at %v", i, window, offsets[i])
+			}
+		}
+	})
+
+	t.Run("cancelable while waiting for the next window", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		r := QuotaReader(ctx, bytes.NewReader(make([]byte, 25)), 10, time.Hour)
+
+		buf := make([]byte, 25)
+		if n, err := r.Read(buf); n != 10 || err != nil {
+			t.Fatalf("expected to read the 10 byte quota but got %d, %v", n, err)
+		}
+		if _, err := r.Read(buf); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestRollingQuotaReader(t *testing.T) {
+	t.Run("under the quota", func(t *testing.T) {
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, RollingQuotaReader(bytes.NewReader(make([]byte, 25)), 30, time.Hour), BufferSize(10))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 25 {
+			t.Fatalf("expected n to be 25 but got %d", n)
+		}
+	})
+
+	t.Run("over the quota", func(t *testing.T) {
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, RollingQuotaReader(bytes.NewReader(make([]byte, 25)), 15, time.Hour), BufferSize(10))
+		if err != ErrQuotaExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", ErrQuotaExceeded, err)
+		}
+		if n != 15 {
+			t.Fatalf("expected the quota of 15 bytes to be copied but got %d", n)
+		}
+	})
+
+	t.Run("bytes leave the rolling window", func(t *testing.T) {
+		const window = 40 * time.Millisecond
+
+		r := RollingQuotaReader(bytes.NewReader(make([]byte, 100)), 10, window)
+		b := make([]byte, 6)
+
+		if n, err := r.Read(b); n != 6 || err != nil {
+			t.Fatalf("expected to read 6 bytes but got %d: %v", n, err)
+		}
+		time.Sleep(window / 2)
+		if n, err := r.Read(b); n != 4 || err != nil {
+			t.Fatalf("expected read to be shortened to the remaining 4 bytes but got %d: %v", n, err)
+		}
+		if _, err := r.Read(b); err != ErrQuotaExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", ErrQuotaExceeded, err)
+		}
+
+		// the first read leaves the window, the second one doesn't yet.
+		time.Sleep(window/2 + window/4)
+		if n, err := r.Read(b); n != 6 || err != nil {
+			t.Fatalf("expected to read 6 bytes but got %d: %v", n, err)
+		}
+		if _, err := r.Read(b); err != ErrQuotaExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", ErrQuotaExceeded, err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package xio
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// rateRamp describes a rate growing linearly from start to target bytes per second over a duration in seconds.
+type rateRamp struct {
+	start  float64
+	target float64
+	over   float64
+}
+
+// elapsed returns the time it takes for n bytes to be allowed, that is the time t at which the integral of the rate
+// from 0 to t reaches n.
+func (r *rateRamp) elapsed(n int64) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	bytes := float64(n)
+
+	// a is the acceleration of the rate during the ramp.
+	var a float64
+	if r.over > 0 {
+		a = (r.target - r.start) / r.over
+	}
+
+	rampBytes := r.start*r.over + a*r.over*r.over/2
+
+	var seconds float64
+	switch {
+	case bytes > rampBytes:
+		seconds = r.over + (bytes-rampBytes)/r.target
+	case a == 0:
+		seconds = bytes / r.start
+	default:
+		// solve a/2 t² + start t - bytes = 0
+		seconds = (-r.start + math.Sqrt(r.start*r.start+2*a*bytes)) / a
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// sleepUntil waits until t or until the context is canceled, whichever happens first.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	return sleep(ctx, time.Until(t))
+}
+
+// sleep waits for d or until the context is canceled, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,115 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRateRamp(t *testing.T) {
+	t.Run("throughput increases over the ramp", func(t *testing.T) {
+		var times []time.Time
+
+		begin := time.Now()
+		n, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				times = append(times, time.Now())
+				return len(b), nil
+			}),
+			bytes.NewReader(make([]byte, 2000)),
+			BufferSize(100),
+			RateRamp(1000, 10000, 200*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 2000 {
+			t.Fatalf("expected n to be 2000 but got %d", n)
+		}
+
+		// With the ramp the first 500 bytes are allowed after ~128ms, and the last 500 bytes take 50ms at the target rate.
+		firstHalf := times[5].Sub(times[0])
+		secondHalf := times[19].Sub(times[14])
+		if firstHalf < 100*time.Millisecond {
+			t.Fatalf("expected the first 500 bytes to take at least 100ms but took %v", firstHalf)
+		}
+		if firstHalf < 2*secondHalf {
+			t.Fatalf("expected throughput to increase but first 500 bytes took %v and last 500 bytes took %v", firstHalf, secondHalf)
+		}
+		if total := time.Since(begin); total < 250*time.Millisecond {
+			t.Fatalf("expected copy to take around 290ms but took %v", total)
+		}
+	})
+
+	t.Run("non-positive rate means no limit", func(t *testing.T) {
+		for _, rate := range []int64{0, -1} {
+			begin := time.Now()
+			n, err := Copy(context.Background(), io.Discard, bytes.NewReader(make([]byte, 1000)), BufferSize(100), RateLimit(rate))
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != 1000 {
+				t.Fatalf("expected n to be 1000 but got %d", n)
+			}
+			if elapsed := time.Since(begin); elapsed > 100*time.Millisecond {
+				t.Fatalf("expected copy not to be throttled with a rate of %d but took %v", rate, elapsed)
+			}
+		}
+	})
+
+	t.Run("ramp from a negative start", func(t *testing.T) {
+		n, err := Copy(
+			context.Background(),
+			io.Discard,
+			bytes.NewReader(make([]byte, 100)),
+			BufferSize(10),
+			RateRamp(-1000, 10000, 10*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 100 {
+			t.Fatalf("expected n to be 100 but got %d", n)
+		}
+	})
+
+	t.Run("cancelable while throttled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		begin := time.Now()
+		_, err := Copy(ctx, io.Discard, bytes.NewReader(make([]byte, 1000)), BufferSize(100), RateLimit(100))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(begin); elapsed > 500*time.Millisecond {
+			t.Fatalf("expected copy to return promptly on cancelation but took %v", elapsed)
+		}
+	})
+}
+
+func TestRateRampElapsed(t *testing.T) {
+	ramp := &rateRamp{start: 1000, target: 10000, over: 0.2}
+
+	for _, tc := range []struct {
+		n        int64
+		expected time.Duration
+	}{
+		{n: 0, expected: 0},
+		{n: 1100, expected: 200 * time.Millisecond},
+		{n: 2000, expected: 290 * time.Millisecond},
+	} {
+		if actual := ramp.elapsed(tc.n); (actual - tc.expected).Abs() > time.Millisecond {
+			t.Fatalf("expected %d bytes to be allowed after %v but got %v", tc.n, tc.expected, actual)
+		}
+	}
+
+	flat := &rateRamp{start: 500, target: 500}
+	if actual := flat.elapsed(1000); actual != 2*time.Second {
+		t.Fatalf("expected 1000 bytes at 500B/s to take 2s but got %v", actual)
+	}
+}
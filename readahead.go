@@ -0,0 +1,81 @@
+package xio
+
+import (
+	"context"
+	"io"
+)
+
+// readAhead reads chunks from src on its own goroutine, using a fixed pool of buffers.
+type readAhead struct {
+	ctx     context.Context
+	chunks  chan readAheadChunk
+	free    chan []byte
+	done    chan struct{}
+	current []byte
+}
+
+type readAheadChunk struct {
+	buf []byte
+	n   int
+	err error
+}
+
+func newReadAhead(ctx context.Context, src io.Reader, buf []byte, depth int) *readAhead {
+	ra := &readAhead{
+		ctx:    ctx,
+		chunks: make(chan readAheadChunk, depth),
+		free:   make(chan []byte, depth),
+		done:   make(chan struct{}),
+	}
+
+	ra.free <- buf
+	for i := 1; i < depth; i++ {
+		ra.free <- make([]byte, len(buf))
+	}
+
+	go ra.run(src)
+	return ra
+}
+
+func (ra *readAhead) run(src io.Reader) {
+	for {
+		var buf []byte
+		select {
+		case buf = <-ra.free:
+		case <-ra.done:
+			return
+		}
+
+		n, err := src.Read(buf)
+
+		select {
+		case ra.chunks <- readAheadChunk{buf: buf, n: n, err: err}:
+		case <-ra.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// read returns the next chunk. The returned data is only valid until the next call.
+func (ra *readAhead) read() ([]byte, error) {
+	if ra.current != nil {
+		ra.free <- ra.current
+		ra.current = nil
+	}
+
+	select {
+	case chunk := <-ra.chunks:
+		ra.current = chunk.buf
+		return chunk.buf[:chunk.n], chunk.err
+	case <-ra.ctx.Done():
+		return nil, ra.ctx.Err()
+	}
+}
+
+// close stops the reading goroutine once its pending read, if any, returns.
+func (ra *readAhead) close() {
+	close(ra.done)
+}
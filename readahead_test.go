@@ -0,0 +1,116 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadAhead(t *testing.T) {
+	t.Run("copies data in order", func(t *testing.T) {
+		data := make([]byte, 1<<20)
+		rand.New(rand.NewSource(1)).Read(data)
+
+		for _, depth := range []int{1, 2, 4, 16} {
+			var dst bytes.Buffer
+
+			n, err := Copy(context.Background(), &dst, bytes.NewReader(data), BufferSize(1000), ReadAhead(depth))
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != int64(len(data)) || !bytes.Equal(dst.Bytes(), data) {
+				t.Fatalf("expected the data to be copied intact with a depth of %d", depth)
+			}
+		}
+	})
+
+	t.Run("bounds the number of buffers read ahead", func(t *testing.T) {
+		var reads atomic.Int64
+		var readsWhileBlocked int64
+
+		var writes int
+		_, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				if writes++; writes == 1 {
+					// give the reader time to fill every free buffer while the first chunk is being written
+					time.Sleep(20 * time.Millisecond)
+					readsWhileBlocked = reads.Load()
+				}
+				return len(b), nil
+			}),
+			io.LimitReader(ReaderFunc(func(b []byte) (int, error) {
+				reads.Add(1)
+				return len(b), nil
+			}), 10*64),
+			BufferSize(64),
+			ReadAhead(3),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if readsWhileBlocked != 3 {
+			t.Fatalf("expected 3 reads with a pool of 3 buffers but got %d", readsWhileBlocked)
+		}
+	})
+
+	t.Run("cancelation aborts cleanly", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var writes int
+		n, err := Copy(
+			ctx,
+			WriterFunc(func(b []byte) (int, error) {
+				if writes++; writes == 3 {
+					cancel()
+				}
+				return len(b), nil
+			}),
+			ReaderFunc(func(b []byte) (int, error) { return len(b), nil }),
+			BufferSize(10),
+			ReadAhead(4),
+		)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected err to be %#q but got %#q", context.Canceled, err)
+		}
+		if n != 30 {
+			t.Fatalf("expected n to be 30 but got %d", n)
+		}
+	})
+}
+
+func BenchmarkReadAhead(b *testing.B) {
+	const chunk = 4096
+
+	slowReader := ReaderFunc(func(p []byte) (int, error) {
+		time.Sleep(100 * time.Microsecond)
+		return len(p), nil
+	})
+	slowWriter := WriterFunc(func(p []byte) (int, error) {
+		time.Sleep(100 * time.Microsecond)
+		return len(p), nil
+	})
+
+	for _, bench := range []struct {
+		name string
+		opts []CopyOption
+	}{
+		{name: "sequential"},
+		{name: "read ahead 4", opts: []CopyOption{ReadAhead(4)}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.SetBytes(100 * chunk)
+			for i := 0; i < b.N; i++ {
+				opts := append([]CopyOption{BufferSize(chunk)}, bench.opts...)
+				if _, err := Copy(context.Background(), slowWriter, io.LimitReader(slowReader, 100*chunk), opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ReadStruct reads exactly as many bytes from r as the fixed-size data out points to takes, cancelably, and decodes
+// them into out with binary.Read, which is convenient for binary protocol headers. out must be a pointer to a fixed-size
+// value, or a slice of them, as accepted by binary.Read. It returns io.EOF if r is exhausted before any byte is read,
+// and io.ErrUnexpectedEOF if it ends part way through.
+func ReadStruct(ctx context.Context, r io.Reader, byteOrder binary.ByteOrder, out any) error {
+	size := binary.Size(out)
+	if size < 0 {
+		return fmt.Errorf("xio: ReadStruct: invalid type %T", out)
+	}
+
+	buf := make([]byte, size)
+	if _, err := ReadFullN(ctx, r, [][]byte{buf}); err != nil {
+		return err
+	}
+	return binary.Read(bytes.NewReader(buf), byteOrder, out)
+}
@@ -0,0 +1,75 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+type testHeader struct {
+	Magic   [4]byte
+	Version uint8
+	Flags   int8
+	Kind    uint16
+	Length  int32
+	ID      uint64
+}
+
+func TestReadStruct(t *testing.T) {
+	expected := testHeader{Magic: [4]byte{'X', 'I', 'O', '!'}, Version: 3, Flags: -2, Kind: 0x0102, Length: -70000, ID: 1 << 40}
+
+	for _, byteOrder := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		t.Run(byteOrder.String(), func(t *testing.T) {
+			var encoded bytes.Buffer
+			if err := binary.Write(&encoded, byteOrder, expected); err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			encoded.WriteString("rest")
+
+			var actual testHeader
+			if err := ReadStruct(context.Background(), &encoded, byteOrder, &actual); err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if actual != expected {
+				t.Fatalf("expected %+v but got %+v", expected, actual)
+			}
+			if encoded.String() != "rest" {
+				t.Fatalf("expected only the struct to be read but %q is left", encoded.String())
+			}
+		})
+	}
+
+	t.Run("short read", func(t *testing.T) {
+		var header testHeader
+		if err := ReadStruct(context.Background(), bytes.NewReader([]byte("XIO!")), binary.BigEndian, &header); err != io.ErrUnexpectedEOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrUnexpectedEOF, err)
+		}
+		if err := ReadStruct(context.Background(), bytes.NewReader(nil), binary.BigEndian, &header); err != io.EOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.EOF, err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		var header testHeader
+		err := ReadStruct(ctx, ReaderFunc(func(b []byte) (int, error) {
+			time.Sleep(5 * time.Millisecond)
+			return copy(b, "X"), nil
+		}), binary.BigEndian, &header)
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		var s string
+		if err := ReadStruct(context.Background(), bytes.NewReader(nil), binary.BigEndian, &s); err == nil {
+			t.Fatalf("expected an error for a variable size type")
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package xio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// CopyRecords copies src into dst as a sequence of fixed-size records of recordSize bytes. When reading or writing
+// a record fails, onError is called with the index of the record and the error. If it returns true the record is
+// skipped and the copy continues with the next one, otherwise the copy is aborted with the error. After a read error,
+// src is expected to resume at the next record boundary. A short final record is reported to onError with
+// io.ErrUnexpectedEOF. The returned count only includes the bytes of records successfully written to dst.
+// CopyRecords accepts the same options as Copy. Its buffer defaults to a single record; options shrinking it, such as
+// MaxMemory, split records across several writes, a failed write being reported for the record it starts in. Calls
+// to onError are serialized but may come from different goroutines when ReadAhead is used. An error is returned without
+// copying anything if recordSize is not positive.
+func CopyRecords(ctx context.Context, dst io.Writer, src io.Reader, recordSize int, onError func(recordIndex int, err error) bool, opts ...CopyOption) (int64, error) {
+	if recordSize <= 0 {
+		return 0, fmt.Errorf("xio: CopyRecords: invalid record size %d", recordSize)
+	}
+	state := &recordState{onError: onError}
+	return Copy(
+		ctx,
+		&recordWriter{w: dst, state: state},
+		&recordReader{r: src, buf: make([]byte, recordSize), state: state},
+		append(opts, Buffer(nil), BufferSize(recordSize))...,
+	)
+}
+
+// recordState carries the index of each record from the reader to the writer, which may run on different
+// goroutines.
+type recordState struct {
+	report  sync.Mutex
+	onError func(recordIndex int, err error) bool
+
+	mu sync.Mutex
+	// queued holds the records read but not yet written, in order.
+	queued []queuedRecord
+}
+
+type queuedRecord struct {
+	index int
+	left  int
+}
+
+func (s *recordState) fail(index int, err error) bool {
+	s.report.Lock()
+	defer s.report.Unlock()
+	return s.onError(index, err)
+}
+
+func (s *recordState) push(index, size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued = append(s.queued, queuedRecord{index: index, left: size})
+}
+
+// pop consumes n bytes from the queued records and returns the index of the record the first of them belongs to.
+func (s *recordState) pop(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queued) == 0 {
+		return -1
+	}
+	index := s.queued[0].index
+	for n > 0 && len(s.queued) > 0 {
+		take := min(n, s.queued[0].left)
+		s.queued[0].left -= take
+		n -= take
+		if s.queued[0].left == 0 {
+			s.queued = s.queued[1:]
+		}
+	}
+	return index
+}
+
+// recordReader reads whole records into its own buffer and hands them out over as many Reads as needed.
+type recordReader struct {
+	r     io.Reader
+	buf   []byte
+	rest  []byte
+	next  int
+	state *recordState
+}
+
+func (rr *recordReader) Read(p []byte) (int, error) {
+	for len(rr.rest) == 0 {
+		n, err := io.ReadFull(rr.r, rr.buf)
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		index := rr.next
+		rr.next++
+		if err == nil {
+			rr.state.push(index, n)
+			rr.rest = rr.buf[:n]
+			break
+		}
+		if !rr.state.fail(index, err) {
+			return 0, err
+		}
+		if err == io.ErrUnexpectedEOF {
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, rr.rest)
+	rr.rest = rr.rest[n:]
+	return n, nil
+}
+
+type recordWriter struct {
+	w     io.Writer
+	state *recordState
+}
+
+func (rw *recordWriter) Write(p []byte) (int, error) {
+	index := rw.state.pop(len(p))
+	n, err := rw.w.Write(p)
+	if err != nil && rw.state.fail(index, err) {
+		return n, nil
+	}
+	return n, err
+}
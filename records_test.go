@@ -0,0 +1,220 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCopyRecords(t *testing.T) {
+	badRecord := errors.New("bad record")
+
+	// newSource yields the given records, returning badRecord for the empty ones.
+	newSource := func(records ...string) ReaderFunc {
+		return func(b []byte) (int, error) {
+			if len(records) == 0 {
+				return 0, io.EOF
+			}
+			record := records[0]
+			records = records[1:]
+			if record == "" {
+				return 0, badRecord
+			}
+			return copy(b, record), nil
+		}
+	}
+
+	t.Run("skips a bad record", func(t *testing.T) {
+		var dst bytes.Buffer
+		var failed []int
+
+		n, err := CopyRecords(
+			context.Background(),
+			&dst,
+			newSource("aaaa", "bbbb", "", "dddd"),
+			4,
+			func(index int, err error) bool {
+				failed = append(failed, index)
+				return err == badRecord
+			},
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 12 {
+			t.Fatalf("expected n to be 12 but got %d", n)
+		}
+		if dst.String() != "aaaabbbbdddd" {
+			t.Fatalf("expected dst to be %q but got %q", "aaaabbbbdddd", dst.String())
+		}
+		if expected := []int{2}; !reflect.DeepEqual(failed, expected) {
+			t.Fatalf("expected failed records to be %v but got %v", expected, failed)
+		}
+	})
+
+	t.Run("aborts on a write error", func(t *testing.T) {
+		writeErr := errors.New("record rejected")
+
+		var failed []int
+		var written []string
+
+		n, err := CopyRecords(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				if string(b) == "cccc" {
+					return 0, writeErr
+				}
+				written = append(written, string(b))
+				return len(b), nil
+			}),
+			newSource("aaaa", "", "cccc", "dddd"),
+			4,
+			func(index int, err error) bool {
+				failed = append(failed, index)
+				return err == badRecord
+			},
+		)
+		if err != writeErr {
+			t.Fatalf("expected err to be %#q but got %#q", writeErr, err)
+		}
+		if n != 4 {
+			t.Fatalf("expected n to be 4 but got %d", n)
+		}
+		if expected := []string{"aaaa"}; !reflect.DeepEqual(written, expected) {
+			t.Fatalf("expected written records to be %v but got %v", expected, written)
+		}
+		if expected := []int{1, 2}; !reflect.DeepEqual(failed, expected) {
+			t.Fatalf("expected failed records to be %v but got %v", expected, failed)
+		}
+	})
+
+	t.Run("short final record", func(t *testing.T) {
+		var failed []error
+
+		n, err := CopyRecords(
+			context.Background(),
+			&bytes.Buffer{},
+			bytes.NewReader([]byte("aaaabb")),
+			4,
+			func(index int, err error) bool {
+				failed = append(failed, err)
+				return true
+			},
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 4 {
+			t.Fatalf("expected n to be 4 but got %d", n)
+		}
+		if expected := []error{io.ErrUnexpectedEOF}; !reflect.DeepEqual(failed, expected) {
+			t.Fatalf("expected failures to be %v but got %v", expected, failed)
+		}
+	})
+	t.Run("buffer smaller than a record", func(t *testing.T) {
+		src := strings.Repeat("a", 512) + strings.Repeat("b", 512)
+		var dst bytes.Buffer
+
+		n, err := CopyRecords(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				if len(b) > 100 {
+					t.Errorf("expected writes of at most 100 bytes but got %d", len(b))
+				}
+				return dst.Write(b)
+			}),
+			strings.NewReader(src),
+			512,
+			func(index int, err error) bool {
+				t.Errorf("unexpected failure of record %d: %v", index, err)
+				return false
+			},
+			MaxMemory(100),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 1024 || dst.String() != src {
+			t.Fatalf("expected both records to be copied but got %d bytes", n)
+		}
+	})
+
+	t.Run("pooled buffer smaller than a record", func(t *testing.T) {
+		pool := &sync.Pool{New: func() any {
+			b := make([]byte, 64)
+			return &b
+		}}
+		ctx := context.WithValue(context.Background(), poolKey{}, pool)
+		src := strings.Repeat("a", 512)
+		var dst bytes.Buffer
+
+		n, err := CopyRecords(
+			ctx,
+			&dst,
+			strings.NewReader(src),
+			512,
+			func(index int, err error) bool { return false },
+			BufferFromContext(poolKey{}),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 512 || dst.String() != src {
+			t.Fatalf("expected the record to be copied but got %d bytes", n)
+		}
+	})
+
+	t.Run("write failures with read ahead", func(t *testing.T) {
+		writeErr := errors.New("write failed")
+		var records []string
+		for i := 0; i < 8; i++ {
+			records = append(records, strings.Repeat(string(rune('a'+i)), 4))
+		}
+		var writes int
+		var failed []int
+
+		n, err := CopyRecords(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				writes++
+				if writes%2 == 0 {
+					return 0, writeErr
+				}
+				return len(b), nil
+			}),
+			newSource(records...),
+			4,
+			func(index int, err error) bool {
+				failed = append(failed, index)
+				return true
+			},
+			ReadAhead(4),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 16 {
+			t.Fatalf("expected n to be 16 but got %d", n)
+		}
+		if expected := []int{1, 3, 5, 7}; !reflect.DeepEqual(failed, expected) {
+			t.Fatalf("expected failed records to be %v but got %v", expected, failed)
+		}
+	})
+	t.Run("invalid record size", func(t *testing.T) {
+		for _, size := range []int{0, -1} {
+			var dst bytes.Buffer
+			n, err := CopyRecords(context.Background(), &dst, strings.NewReader("aaaa"), size, func(index int, err error) bool {
+				t.Errorf("unexpected failure of record %d: %v", index, err)
+				return true
+			})
+			if err == nil || n != 0 || dst.Len() != 0 {
+				t.Fatalf("expected a record size of %d to fail without copying anything but got %d bytes and %#q", size, n, err)
+			}
+		}
+	})
+}
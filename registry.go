@@ -0,0 +1,59 @@
+package xio
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CopyStatus describes a copy in flight.
+type CopyStatus struct {
+	// ID identifies the copy among the ones registered since the program started.
+	ID uint64
+	// Started is when the copy started.
+	Started time.Time
+	// Written is the number of bytes written to dst so far.
+	Written int64
+}
+
+var registry = struct {
+	sync.Mutex
+	nextID uint64
+	copies map[uint64]registeredCopy
+}{copies: map[uint64]registeredCopy{}}
+
+type registeredCopy struct {
+	started time.Time
+	written *atomic.Int64
+}
+
+// ActiveCopies returns the status of the copies in flight that were started with the Register option, ordered by ID.
+// It is safe to call concurrently with any number of copies.
+func ActiveCopies() []CopyStatus {
+	registry.Lock()
+	defer registry.Unlock()
+
+	statuses := make([]CopyStatus, 0, len(registry.copies))
+	for id, c := range registry.copies {
+		statuses = append(statuses, CopyStatus{ID: id, Started: c.started, Written: c.written.Load()})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	return statuses
+}
+
+// register adds a copy to the registry and returns the func removing it.
+func register(started time.Time, written *atomic.Int64) (unregister func()) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.nextID++
+	id := registry.nextID
+	registry.copies[id] = registeredCopy{started: started, written: written}
+
+	return func() {
+		registry.Lock()
+		defer registry.Unlock()
+		delete(registry.copies, id)
+	}
+}
@@ -0,0 +1,73 @@
+package xio
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestActiveCopies(t *testing.T) {
+	const copies = 3
+
+	// an unregistered copy is not listed
+	if _, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+		if active := ActiveCopies(); len(active) != 0 {
+			t.Errorf("expected no active copies but got %v", active)
+		}
+		return len(b), nil
+	}), strings.NewReader("unlisted")); err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+
+	written := make(chan struct{}, copies)
+	unblock := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < copies; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var once sync.Once
+			_, err := Copy(
+				context.Background(),
+				WriterFunc(func(b []byte) (int, error) {
+					once.Do(func() { written <- struct{}{} })
+					<-unblock
+					return len(b), nil
+				}),
+				strings.NewReader("hello world"),
+				BufferSize(5),
+				Register(),
+			)
+			if err != nil {
+				t.Errorf("expected err to be nil but got %#q", err)
+			}
+		}()
+	}
+
+	for i := 0; i < copies; i++ {
+		<-written
+	}
+
+	active := ActiveCopies()
+	if len(active) != copies {
+		t.Fatalf("expected %d active copies but got %v", copies, active)
+	}
+	for i, status := range active {
+		if i > 0 && status.ID <= active[i-1].ID {
+			t.Fatalf("expected copies to be ordered by ID but got %v", active)
+		}
+		if status.Started.IsZero() {
+			t.Fatalf("expected start time to be set but got %v", status)
+		}
+	}
+
+	close(unblock)
+	wg.Wait()
+
+	if active := ActiveCopies(); len(active) != 0 {
+		t.Fatalf("expected copies to be unregistered once finished but got %v", active)
+	}
+}
@@ -0,0 +1,60 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// TimedChunk is a chunk of a recorded stream, along with the time elapsed since the previous chunk arrived, or since
+// the stream started for the first chunk.
+type TimedChunk struct {
+	Delay time.Duration
+	Data  []byte
+}
+
+// ReplayReader returns a reader that replays a recorded stream with its original timing, for realistic tests of
+// timeouts and rate limits. The data of every chunk becomes available Delay after the previous one did, the first
+// Read starting the clock, so that a slow consumer doesn't shift the following chunks. A Read returns the data of a
+// single chunk at most, and io.EOF after the last chunk. See ReplayReaderContext to cancel the delays.
+func ReplayReader(events []TimedChunk) io.Reader {
+	return ReplayReaderContext(context.Background(), events)
+}
+
+// ReplayReaderContext is like ReplayReader, but waiting for a chunk is cut short when the context is canceled, in which
+// case Read returns the context error.
+func ReplayReaderContext(ctx context.Context, events []TimedChunk) io.Reader {
+	return &replayReader{ctx: ctx, events: events}
+}
+
+type replayReader struct {
+	ctx    context.Context
+	events []TimedChunk
+	due    time.Time
+	// current is the data left of the chunk being read, once it is due.
+	current []byte
+}
+
+func (rr *replayReader) Read(p []byte) (int, error) {
+	if len(rr.current) == 0 {
+		if len(rr.events) == 0 {
+			return 0, io.EOF
+		}
+		if rr.due.IsZero() {
+			rr.due = time.Now()
+		}
+
+		event := rr.events[0]
+		due := rr.due.Add(event.Delay)
+		if err := sleepUntil(rr.ctx, due); err != nil {
+			return 0, err
+		}
+		rr.due = due
+		rr.events = rr.events[1:]
+		rr.current = event.Data
+	}
+
+	n := copy(p, rr.current)
+	rr.current = rr.current[n:]
+	return n, nil
+}
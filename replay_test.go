@@ -0,0 +1,73 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplayReader(t *testing.T) {
+	events := []TimedChunk{
+		{Delay: 0, Data: []byte("first")},
+		{Delay: 30 * time.Millisecond, Data: []byte("second")},
+		{Delay: 10 * time.Millisecond, Data: []byte("third")},
+		{Delay: 40 * time.Millisecond, Data: []byte("fourth")},
+	}
+
+	t.Run("recorded timing", func(t *testing.T) {
+		start := time.Now()
+		var offsets []time.Duration
+		var chunks []string
+
+		_, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+			offsets = append(offsets, time.Since(start))
+			chunks = append(chunks, string(b))
+			return len(b), nil
+		}), ReplayReader(events))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		if len(chunks) != len(events) {
+			t.Fatalf("expected %d chunks but got %q", len(events), chunks)
+		}
+		var at time.Duration
+		for i, event := range events {
+			at += event.Delay
+			if chunks[i] != string(event.Data) {
+				t.Fatalf("expected chunk %d to be %q but got %q", i, event.Data, chunks[i])
+			}
+			if offsets[i] < at || offsets[i] > at+15*time.Millisecond {
+				t.Fatalf("expected chunk %d to arrive around %v but got %v", i, at, offsets[i])
+			}
+		}
+	})
+
+	t.Run("small reads", func(t *testing.T) {
+		var dst []byte
+		if _, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+			dst = append(dst, b...)
+			return len(b), nil
+		}), ReplayReader(events), BufferSize(4)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(dst) != "firstsecondthirdfourth" {
+			t.Fatalf("expected the whole stream but got %q", dst)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		r := ReplayReaderContext(ctx, []TimedChunk{{Delay: time.Hour, Data: []byte("never")}})
+		start := time.Now()
+		if _, err := r.Read(make([]byte, 8)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("expected the wait to be cut short but took %v", elapsed)
+		}
+	})
+}
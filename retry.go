@@ -0,0 +1,62 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// retryAfterReader retries reads from r that fail with an error carrying a Retry-After hint once the hinted duration
+// has elapsed.
+type retryAfterReader struct {
+	ctx     context.Context
+	r       io.Reader
+	extract func(error) (time.Duration, bool)
+}
+
+func (rr *retryAfterReader) Read(p []byte) (int, error) {
+	for {
+		n, err := rr.r.Read(p)
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+		d, ok := rr.extract(err)
+		if !ok {
+			return n, err
+		}
+		// Data read along with the error is handed over first, the read is retried on the next call.
+		if n > 0 {
+			return n, nil
+		}
+		if err := sleep(rr.ctx, d); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// retryAfterWriter retries writes to w that fail with an error carrying a Retry-After hint once the hinted duration
+// has elapsed, resuming with the bytes that were not written.
+type retryAfterWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	extract func(error) (time.Duration, bool)
+}
+
+func (rw *retryAfterWriter) Write(p []byte) (int, error) {
+	var written int
+	for written < len(p) {
+		n, err := rw.w.Write(p[written:])
+		written += n
+		if err == nil {
+			continue
+		}
+		d, ok := rw.extract(err)
+		if !ok {
+			return written, err
+		}
+		if err := sleep(rw.ctx, d); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,108 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type retryAfterError struct{ after time.Duration }
+
+func (e retryAfterError) Error() string { return "service unavailable" }
+
+func extractRetryAfter(err error) (time.Duration, bool) {
+	var retryErr retryAfterError
+	if errors.As(err, &retryErr) {
+		return retryErr.after, true
+	}
+	return 0, false
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Run("read", func(t *testing.T) {
+		src := strings.NewReader("hello world")
+		var failed bool
+
+		var dst bytes.Buffer
+		start := time.Now()
+		n, err := Copy(
+			context.Background(),
+			&dst,
+			ReaderFunc(func(b []byte) (int, error) {
+				if !failed && src.Len() == 7 {
+					failed = true
+					return 0, retryAfterError{after: 20 * time.Millisecond}
+				}
+				return src.Read(b)
+			}),
+			BufferSize(4),
+			RetryAfter(extractRetryAfter),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 || dst.String() != "hello world" {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+		}
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Fatalf("expected copy to wait for the retry-after duration but took %v", elapsed)
+		}
+	})
+
+	t.Run("write", func(t *testing.T) {
+		var dst bytes.Buffer
+		var failed bool
+
+		n, err := Copy(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				if !failed {
+					failed = true
+					dst.Write(b[:1])
+					return 1, retryAfterError{after: time.Millisecond}
+				}
+				return dst.Write(b)
+			}),
+			strings.NewReader("hello world"),
+			RetryAfter(extractRetryAfter),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 11 || dst.String() != "hello world" {
+			t.Fatalf("expected all data to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+
+	t.Run("other errors abort", func(t *testing.T) {
+		readErr := errors.New("reader broke!")
+
+		_, err := Copy(
+			context.Background(),
+			&bytes.Buffer{},
+			ReaderFunc(func(b []byte) (int, error) { return 0, readErr }),
+			RetryAfter(extractRetryAfter),
+		)
+		if err != readErr {
+			t.Fatalf("expected err to be %#q but got %#q", readErr, err)
+		}
+	})
+
+	t.Run("wait is cancelable", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := Copy(
+			ctx,
+			&bytes.Buffer{},
+			ReaderFunc(func(b []byte) (int, error) { return 0, retryAfterError{after: time.Hour} }),
+			RetryAfter(extractRetryAfter),
+		)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be context deadline exceeded but got %v", err)
+		}
+	})
+}
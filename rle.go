@@ -0,0 +1,70 @@
+package xio
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrCorruptRLE is returned by an RLEReader when its input ends in the middle of a (count, value) pair.
+var ErrCorruptRLE = errors.New("corrupt run-length encoded data")
+
+// RLEReader returns a reader decoding the run-length encoded data of r as it is read. The encoding is a sequence of
+// byte pairs, a count followed by the value to repeat count times. A count of zero yields nothing. If r ends with an
+// incomplete pair the reader fails with ErrCorruptRLE.
+func RLEReader(r io.Reader) io.Reader {
+	return &rleReader{r: r}
+}
+
+type rleReader struct {
+	r     io.Reader
+	raw   [512]byte
+	pairs []byte
+	count int
+	value byte
+	err   error
+}
+
+func (rr *rleReader) Read(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		if rr.count == 0 {
+			if len(rr.pairs) < 2 {
+				if rr.err != nil {
+					break
+				}
+				rr.fill()
+				continue
+			}
+			rr.count, rr.value = int(rr.pairs[0]), rr.pairs[1]
+			rr.pairs = rr.pairs[2:]
+			continue
+		}
+
+		run := len(p) - n
+		if run > rr.count {
+			run = rr.count
+		}
+		for i := range p[n : n+run] {
+			p[n+i] = rr.value
+		}
+		n += run
+		rr.count -= run
+	}
+
+	if n > 0 {
+		return n, nil
+	}
+	if rr.err == io.EOF && len(rr.pairs) == 1 {
+		rr.pairs = nil
+		rr.err = ErrCorruptRLE
+	}
+	return 0, rr.err
+}
+
+// fill reads more encoded data, keeping any incomplete pair at the front.
+func (rr *rleReader) fill() {
+	start := copy(rr.raw[:], rr.pairs)
+	n, err := rr.r.Read(rr.raw[start:])
+	rr.pairs = rr.raw[:start+n]
+	rr.err = err
+}
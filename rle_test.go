@@ -0,0 +1,60 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestRLEReader(t *testing.T) {
+	t.Run("decodes runs", func(t *testing.T) {
+		encoded := []byte{3, 'a', 1, 'b', 0, 'z', 5, 'c', 200, '.'}
+		expected := "aaabccccc" + string(bytes.Repeat([]byte{'.'}, 200))
+
+		var dst bytes.Buffer
+		// a small buffer splits both pairs and runs across reads
+		n, err := Copy(context.Background(), &dst, RLEReader(ReaderFunc(bytes.NewReader(encoded).Read)), BufferSize(3))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != int64(len(expected)) || dst.String() != expected {
+			t.Fatalf("expected %q but got %q", expected, dst.String())
+		}
+	})
+
+	t.Run("pairs split across reads", func(t *testing.T) {
+		encoded := []byte{2, 'x', 3, 'y'}
+		var chunks [][]byte
+		for _, b := range encoded {
+			chunks = append(chunks, []byte{b})
+		}
+
+		src := ReaderFunc(func(b []byte) (int, error) {
+			if len(chunks) == 0 {
+				return 0, io.EOF
+			}
+			n := copy(b, chunks[0])
+			chunks = chunks[1:]
+			return n, nil
+		})
+
+		actual, err := ReadAll(context.Background(), RLEReader(src))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(actual) != "xxyyy" {
+			t.Fatalf("expected %q but got %q", "xxyyy", actual)
+		}
+	})
+
+	t.Run("odd length input", func(t *testing.T) {
+		actual, err := ReadAll(context.Background(), RLEReader(bytes.NewReader([]byte{2, 'a', 4})))
+		if err != ErrCorruptRLE {
+			t.Fatalf("expected err to be %#q but got %#q", ErrCorruptRLE, err)
+		}
+		if string(actual) != "aa" {
+			t.Fatalf("expected the complete runs to be decoded but got %q", actual)
+		}
+	})
+}
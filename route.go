@@ -0,0 +1,28 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// sniffLen is the number of bytes considered by http.DetectContentType.
+const sniffLen = 512
+
+// CopyRouted sniffs the content type of the first 512 bytes of src using http.DetectContentType, calls route to pick
+// a destination for that content type, and then copies the full stream, sniffed bytes included, to that destination.
+// Sniffing is cancelable like the copy itself. An error returned by route aborts before anything is written.
+func CopyRouted(ctx context.Context, src io.Reader, route func(contentType string) (io.Writer, error), opts ...CopyOption) (int64, error) {
+	var prefix bytes.Buffer
+	if _, err := CopyN(ctx, &prefix, src, sniffLen); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	dst, err := route(http.DetectContentType(prefix.Bytes()))
+	if err != nil {
+		return 0, err
+	}
+
+	return Copy(ctx, dst, io.MultiReader(&prefix, src), opts...)
+}
@@ -0,0 +1,77 @@
+package xio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCopyRouted(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(strings.Repeat("compressed content ", 100)))
+	zw.Close()
+
+	plain := strings.Repeat("plain text content\n", 100)
+
+	for _, tc := range []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{name: "gzip", data: compressed.Bytes(), expected: "archives"},
+		{name: "text", data: []byte(plain), expected: "text"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			writers := map[string]*bytes.Buffer{"archives": {}, "text": {}}
+
+			n, err := CopyRouted(
+				context.Background(),
+				bytes.NewReader(tc.data),
+				func(contentType string) (io.Writer, error) {
+					switch {
+					case contentType == "application/x-gzip":
+						return writers["archives"], nil
+					case strings.HasPrefix(contentType, "text/plain"):
+						return writers["text"], nil
+					default:
+						return nil, errors.New("unexpected content type: " + contentType)
+					}
+				},
+				BufferSize(64),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != int64(len(tc.data)) {
+				t.Fatalf("expected n to be %d but got %d", len(tc.data), n)
+			}
+			for name, w := range writers {
+				if name == tc.expected && !bytes.Equal(w.Bytes(), tc.data) {
+					t.Fatalf("expected %s writer to receive the full stream", name)
+				}
+				if name != tc.expected && w.Len() > 0 {
+					t.Fatalf("expected %s writer to receive nothing but got %d bytes", name, w.Len())
+				}
+			}
+		})
+	}
+
+	t.Run("route error", func(t *testing.T) {
+		routeErr := errors.New("no route")
+
+		n, err := CopyRouted(context.Background(), strings.NewReader(plain), func(string) (io.Writer, error) {
+			return nil, routeErr
+		})
+		if err != routeErr {
+			t.Fatalf("expected err to be %#q but got %#q", routeErr, err)
+		}
+		if n != 0 {
+			t.Fatalf("expected n to be 0 but got %d", n)
+		}
+	})
+}
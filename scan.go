@@ -0,0 +1,48 @@
+package xio
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// ScanCopy tokenizes src using the split function and writes each token to dst, joined by sep. It is cancelable
+// between tokens via the context, and accepts the same options as Copy. This allows a stream to be reframed during
+// a copy using the split functions of the standard bufio package, for example: bufio.ScanWords joined by a space.
+// The returned count is the number of bytes written to dst, separators included.
+func ScanCopy(ctx context.Context, dst io.Writer, src io.Reader, split bufio.SplitFunc, sep []byte, opts ...CopyOption) (int64, error) {
+	scanner := bufio.NewScanner(src)
+	scanner.Split(split)
+	return Copy(ctx, dst, &scanReader{scanner: scanner, sep: sep}, opts...)
+}
+
+// scanReader yields the tokens of a scanner joined by sep. Each Read returns at most one token so that Copy checks
+// the context between tokens.
+type scanReader struct {
+	scanner *bufio.Scanner
+	sep     []byte
+	buf     []byte
+	pending []byte
+	started bool
+}
+
+func (r *scanReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+		r.buf = r.buf[:0]
+		if r.started {
+			r.buf = append(r.buf, r.sep...)
+		}
+		r.buf = append(r.buf, r.scanner.Bytes()...)
+		r.pending = r.buf
+		r.started = true
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
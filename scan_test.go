@@ -0,0 +1,79 @@
+package xio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScanCopy(t *testing.T) {
+	t.Run("words joined by spaces", func(t *testing.T) {
+		var dst bytes.Buffer
+
+		n, err := ScanCopy(
+			context.Background(),
+			&dst,
+			strings.NewReader("  hello\t\tbig \n world  "),
+			bufio.ScanWords,
+			[]byte(" "),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		expected := "hello big world"
+		if actual := dst.String(); actual != expected {
+			t.Fatalf("expected output to be %q but got %q", expected, actual)
+		}
+		if n != int64(len(expected)) {
+			t.Fatalf("expected n to be %d but got %d", len(expected), n)
+		}
+	})
+
+	t.Run("tokens larger than the copy buffer", func(t *testing.T) {
+		var dst bytes.Buffer
+
+		_, err := ScanCopy(
+			context.Background(),
+			&dst,
+			strings.NewReader("abcdefgh ijklmnop"),
+			bufio.ScanWords,
+			[]byte(", "),
+			BufferSize(3),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if expected, actual := "abcdefgh, ijklmnop", dst.String(); actual != expected {
+			t.Fatalf("expected output to be %q but got %q", expected, actual)
+		}
+	})
+
+	t.Run("cancelable between tokens", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var tokens []string
+
+		_, err := ScanCopy(
+			ctx,
+			WriterFunc(func(b []byte) (int, error) {
+				tokens = append(tokens, string(b))
+				cancel()
+				return len(b), nil
+			}),
+			strings.NewReader("one two three"),
+			bufio.ScanWords,
+			[]byte(" "),
+		)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected error to be context canceled but got %v", err)
+		}
+		if len(tokens) != 1 || tokens[0] != "one" {
+			t.Fatalf("expected only the first token to be written but got %q", tokens)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package xio
+
+import (
+	"io"
+	"time"
+)
+
+// Clock abstracts the passing of time for the readers that wait on it, so that tests can control it.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the Clock of the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// ScheduledReader returns a reader that replays data following a trace: for each entry of schedule, in order, it
+// waits until At has elapsed since the first Read and then makes the next N bytes of data available. A Read returns
+// at most the bytes of a single entry, so entries are never merged even when they are due. Data not covered by the
+// schedule is returned after the last entry, and io.EOF once data is exhausted. Entries are expected to be sorted by
+// At, and an entry with a negative N is taken as one of 0 bytes, only delaying the entries after it. It waits on the SystemClock, see ScheduledReaderClock to control time.
+func ScheduledReader(data []byte, schedule []struct {
+	At time.Duration
+	N  int
+}) io.Reader {
+	return ScheduledReaderClock(SystemClock, data, schedule)
+}
+
+// ScheduledReaderClock is like ScheduledReader but waits on clock.
+func ScheduledReaderClock(clock Clock, data []byte, schedule []struct {
+	At time.Duration
+	N  int
+}) io.Reader {
+	return &scheduledReader{clock: clock, data: data, schedule: schedule}
+}
+
+type scheduledReader struct {
+	clock    Clock
+	data     []byte
+	schedule []struct {
+		At time.Duration
+		N  int
+	}
+	start time.Time
+	// available is the number of bytes of the current entry left to return.
+	available int
+}
+
+func (sr *scheduledReader) Read(p []byte) (int, error) {
+	if sr.start.IsZero() {
+		sr.start = sr.clock.Now()
+	}
+	if len(sr.data) == 0 {
+		return 0, io.EOF
+	}
+
+	if sr.available == 0 {
+		if len(sr.schedule) == 0 {
+			sr.available = len(sr.data)
+		} else {
+			entry := sr.schedule[0]
+			sr.schedule = sr.schedule[1:]
+			if wait := sr.start.Add(entry.At).Sub(sr.clock.Now()); wait > 0 {
+				sr.clock.Sleep(wait)
+			}
+			sr.available = min(max(entry.N, 0), len(sr.data))
+		}
+	}
+
+	if len(p) > sr.available {
+		p = p[:sr.available]
+	}
+	n := copy(p, sr.data)
+	sr.data = sr.data[n:]
+	sr.available -= n
+	return n, nil
+}
@@ -0,0 +1,136 @@
+package xio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestScheduledReader(t *testing.T) {
+	schedule := []struct {
+		At time.Duration
+		N  int
+	}{
+		{At: 0, N: 3},
+		{At: 20 * time.Millisecond, N: 4},
+		{At: 50 * time.Millisecond, N: 2},
+	}
+
+	t.Run("fake clock", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		start := clock.now
+
+		type delivery struct {
+			at   time.Duration
+			data string
+		}
+		var deliveries []delivery
+
+		_, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+			deliveries = append(deliveries, delivery{at: clock.now.Sub(start), data: string(b)})
+			return len(b), nil
+		}), ScheduledReaderClock(clock, []byte("abcdefghijkl"), schedule))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		expected := []delivery{
+			{at: 0, data: "abc"},
+			{at: 20 * time.Millisecond, data: "defg"},
+			{at: 50 * time.Millisecond, data: "hi"},
+			{at: 50 * time.Millisecond, data: "jkl"},
+		}
+		if len(deliveries) != len(expected) {
+			t.Fatalf("expected deliveries %v but got %v", expected, deliveries)
+		}
+		for i := range expected {
+			if deliveries[i] != expected[i] {
+				t.Fatalf("expected deliveries %v but got %v", expected, deliveries)
+			}
+		}
+	})
+
+	t.Run("small reads", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		start := clock.now
+
+		r := ScheduledReaderClock(clock, []byte("abcdefghi"), schedule)
+		buf := make([]byte, 2)
+
+		for _, expected := range []struct {
+			at   time.Duration
+			data string
+		}{
+			{at: 0, data: "ab"},
+			{at: 0, data: "c"},
+			{at: 20 * time.Millisecond, data: "de"},
+			{at: 20 * time.Millisecond, data: "fg"},
+			{at: 50 * time.Millisecond, data: "hi"},
+		} {
+			n, err := r.Read(buf)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if at := clock.now.Sub(start); string(buf[:n]) != expected.data || at != expected.at {
+				t.Fatalf("expected %q at %v but got %q at %v", expected.data, expected.at, buf[:n], at)
+			}
+		}
+	})
+
+	t.Run("negative entry", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		start := clock.now
+
+		var data []string
+		n, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+			data = append(data, string(b))
+			return len(b), nil
+		}), ScheduledReaderClock(clock, []byte("abcdef"), []struct {
+			At time.Duration
+			N  int
+		}{
+			{At: 0, N: 2},
+			{At: 10 * time.Millisecond, N: -1},
+			{At: 30 * time.Millisecond, N: 2},
+		}))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 6 {
+			t.Fatalf("expected n to be 6 but got %d", n)
+		}
+		if expected := []string{"ab", "cd", "ef"}; !reflect.DeepEqual(data, expected) {
+			t.Fatalf("expected chunks %q but got %q", expected, data)
+		}
+		if at := clock.now.Sub(start); at != 30*time.Millisecond {
+			t.Fatalf("expected the negative entry to only delay the next one but ended at %v", at)
+		}
+	})
+
+	t.Run("system clock", func(t *testing.T) {
+		start := time.Now()
+		var offsets []time.Duration
+
+		_, err := Copy(context.Background(), WriterFunc(func(b []byte) (int, error) {
+			offsets = append(offsets, time.Since(start))
+			return len(b), nil
+		}), ScheduledReader([]byte("abcdefghi"), schedule))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		for i, offset := range offsets {
+			if at := schedule[i].At; offset < at || offset > at+20*time.Millisecond {
+				t.Fatalf("expected chunk %d to be delivered around %v but got %v", i, at, offset)
+			}
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package xio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrOutOfSequence is returned by a SequencedReader when a frame is missing or out of order.
+var ErrOutOfSequence = errors.New("frame out of sequence")
+
+// sequenceHeaderLen is the length of the header of a sequenced frame: an 8 byte sequence number and a 4 byte length.
+const sequenceHeaderLen = 12
+
+// SequencedWriter returns a writer that writes every Write to w as a frame prefixed with a big endian header holding a
+// sequence number, starting at 0 and incremented with every frame, and the length of the payload. Reading the stream
+// back with SequencedReader detects dropped or reordered frames. The count returned by Write only includes the payload.
+func SequencedWriter(w io.Writer) io.Writer {
+	return &sequencedWriter{w: w}
+}
+
+type sequencedWriter struct {
+	w     io.Writer
+	seq   uint64
+	frame []byte
+}
+
+func (sw *sequencedWriter) Write(p []byte) (int, error) {
+	sw.frame = append(sw.frame[:0], make([]byte, sequenceHeaderLen)...)
+	binary.BigEndian.PutUint64(sw.frame, sw.seq)
+	binary.BigEndian.PutUint32(sw.frame[8:], uint32(len(p)))
+	sw.frame = append(sw.frame, p...)
+
+	n, err := sw.w.Write(sw.frame)
+	if n -= sequenceHeaderLen; n < 0 {
+		n = 0
+	}
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err == nil {
+		sw.seq++
+	}
+	return n, err
+}
+
+// SequencedReader returns a reader that validates and strips the frames written by a SequencedWriter, yielding the
+// payloads. A frame whose sequence number isn't the one expected fails the read with an error wrapping
+// ErrOutOfSequence, and a stream ending within a frame with io.ErrUnexpectedEOF.
+func SequencedReader(r io.Reader) io.Reader {
+	return &sequencedReader{r: r}
+}
+
+type sequencedReader struct {
+	r         io.Reader
+	seq       uint64
+	remaining int
+	err       error
+}
+
+func (sr *sequencedReader) Read(p []byte) (int, error) {
+	for sr.remaining == 0 {
+		if sr.err != nil {
+			return 0, sr.err
+		}
+		sr.err = sr.next()
+	}
+
+	if len(p) > sr.remaining {
+		p = p[:sr.remaining]
+	}
+	n, err := sr.r.Read(p)
+	sr.remaining -= n
+	if err == io.EOF && sr.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// next reads and validates the header of the next frame.
+func (sr *sequencedReader) next() error {
+	var header [sequenceHeaderLen]byte
+	if _, err := io.ReadFull(sr.r, header[:]); err != nil {
+		return err
+	}
+	if seq := binary.BigEndian.Uint64(header[:]); seq != sr.seq {
+		return fmt.Errorf("%w: expected frame %d but got %d", ErrOutOfSequence, sr.seq, seq)
+	}
+	sr.seq++
+	sr.remaining = int(binary.BigEndian.Uint32(header[8:]))
+	return nil
+}
@@ -0,0 +1,58 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSequencedWriter(t *testing.T) {
+	var framed bytes.Buffer
+	n, err := Copy(context.Background(), SequencedWriter(&framed), strings.NewReader("ordered stream"), BufferSize(4))
+	if err != nil {
+		t.Fatalf("expected err to be nil but got %#q", err)
+	}
+	if n != 14 {
+		t.Fatalf("expected n to count the payload only but got %d", n)
+	}
+
+	frameLen := func(payload int) int { return sequenceHeaderLen + payload }
+	stream := framed.Bytes()
+	if expected := frameLen(4)*3 + frameLen(2); len(stream) != expected {
+		t.Fatalf("expected %d framed bytes but got %d", expected, len(stream))
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, SequencedReader(bytes.NewReader(stream)), BufferSize(3))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 14 || dst.String() != "ordered stream" {
+			t.Fatalf("expected the payloads to be read back but got %d bytes: %q", n, dst.String())
+		}
+	})
+
+	t.Run("gap in sequence", func(t *testing.T) {
+		dropped := append(append([]byte{}, stream[:frameLen(4)]...), stream[2*frameLen(4):]...)
+
+		var dst bytes.Buffer
+		_, err := Copy(context.Background(), &dst, SequencedReader(bytes.NewReader(dropped)))
+		if !errors.Is(err, ErrOutOfSequence) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrOutOfSequence, err)
+		}
+		if dst.String() != "orde" {
+			t.Fatalf("expected the frames before the gap to be read but got %q", dst.String())
+		}
+	})
+
+	t.Run("truncated frame", func(t *testing.T) {
+		_, err := Copy(context.Background(), &bytes.Buffer{}, SequencedReader(bytes.NewReader(stream[:len(stream)-1])))
+		if err != io.ErrUnexpectedEOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrUnexpectedEOF, err)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package xio
+
+import "io"
+
+// shardWriter spreads the data written to it across shards of up to size bytes, or writes it all to a single shard when
+// size is not positive.
+type shardWriter struct {
+	size    int64
+	open    func(index int) (io.WriteCloser, error)
+	shard   io.WriteCloser
+	index   int
+	written int64
+}
+
+func (sw *shardWriter) Write(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		if sw.shard == nil || (sw.size > 0 && sw.written == sw.size) {
+			if err := sw.Close(); err != nil {
+				return n, err
+			}
+			shard, err := sw.open(sw.index)
+			if err != nil {
+				return n, err
+			}
+			sw.shard = shard
+			sw.index++
+			sw.written = 0
+		}
+
+		chunk := p[n:]
+		if remaining := sw.size - sw.written; sw.size > 0 && int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+
+		m, err := sw.shard.Write(chunk)
+		n += m
+		sw.written += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Close closes the current shard if there is one.
+func (sw *shardWriter) Close() error {
+	if sw.shard == nil {
+		return nil
+	}
+	shard := sw.shard
+	sw.shard = nil
+	return shard.Close()
+}
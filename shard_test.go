@@ -0,0 +1,132 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type shardBuffer struct {
+	bytes.Buffer
+	closed   bool
+	closeErr error
+	writeErr error
+}
+
+func (b *shardBuffer) Write(p []byte) (int, error) {
+	if b.writeErr != nil {
+		return 0, b.writeErr
+	}
+	return b.Buffer.Write(p)
+}
+
+func (b *shardBuffer) Close() error {
+	b.closed = true
+	return b.closeErr
+}
+
+func TestShardOutput(t *testing.T) {
+	t.Run("splits into shards", func(t *testing.T) {
+		var shards []*shardBuffer
+
+		n, err := Copy(
+			context.Background(),
+			io.Discard,
+			strings.NewReader("0123456789abcdefghijklmno"),
+			BufferSize(4),
+			ShardOutput(10, func(index int) (io.WriteCloser, error) {
+				if index != len(shards) {
+					t.Errorf("expected shard index %d but got %d", len(shards), index)
+				}
+				for i, shard := range shards {
+					if !shard.closed {
+						t.Errorf("expected shard %d to be closed before opening shard %d", i, index)
+					}
+				}
+				shards = append(shards, &shardBuffer{})
+				return shards[index], nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 25 {
+			t.Fatalf("expected n to be 25 but got %d", n)
+		}
+
+		var contents []string
+		for i, shard := range shards {
+			if !shard.closed {
+				t.Fatalf("expected shard %d to be closed", i)
+			}
+			contents = append(contents, shard.String())
+		}
+		if expected := []string{"0123456789", "abcdefghij", "klmno"}; !reflect.DeepEqual(contents, expected) {
+			t.Fatalf("expected shards to be %q but got %q", expected, contents)
+		}
+	})
+
+	t.Run("non-positive size means a single shard", func(t *testing.T) {
+		for _, size := range []int64{0, -1} {
+			var shards []*shardBuffer
+
+			n, err := Copy(
+				context.Background(),
+				io.Discard,
+				strings.NewReader("0123456789abcdefghijklmno"),
+				BufferSize(4),
+				ShardOutput(size, func(index int) (io.WriteCloser, error) {
+					shards = append(shards, &shardBuffer{})
+					return shards[index], nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != 25 {
+				t.Fatalf("expected n to be 25 but got %d", n)
+			}
+			if len(shards) != 1 || shards[0].String() != "0123456789abcdefghijklmno" || !shards[0].closed {
+				t.Fatalf("expected a single closed shard holding everything with a size of %d but got %d shards", size, len(shards))
+			}
+		}
+	})
+
+	t.Run("close error is returned", func(t *testing.T) {
+		closeErr := errors.New("close failed")
+
+		_, err := Copy(
+			context.Background(),
+			io.Discard,
+			strings.NewReader("0123456789"),
+			ShardOutput(100, func(index int) (io.WriteCloser, error) {
+				return &shardBuffer{closeErr: closeErr}, nil
+			}),
+		)
+		if err != closeErr {
+			t.Fatalf("expected err to be %#q but got %#q", closeErr, err)
+		}
+	})
+
+	t.Run("write error takes precedence and shard is closed", func(t *testing.T) {
+		writeErr := errors.New("write failed")
+		shard := &shardBuffer{writeErr: writeErr, closeErr: errors.New("close failed")}
+
+		_, err := Copy(
+			context.Background(),
+			io.Discard,
+			strings.NewReader("0123456789"),
+			ShardOutput(100, func(index int) (io.WriteCloser, error) { return shard, nil }),
+		)
+		if err != writeErr {
+			t.Fatalf("expected err to be %#q but got %#q", writeErr, err)
+		}
+		if !shard.closed {
+			t.Fatal("expected shard to be closed after a failure")
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package xio
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// SharedLimiter caps the combined rate of all the copies it is given to with the SharedLimit option, so that they
+// share a common bandwidth. When the limit is saturated, the bandwidth is distributed between the copies by weighted
+// fair queuing: every chunk is tagged with a virtual finish time, the virtual finish time of the previous chunk of the
+// same copy, or the current virtual time if later, plus its size divided by the weight of the copy, and chunks are
+// allowed through in the order of their tags at the shared rate. Copies of priority class c, set with WithPriority,
+// weigh c+1, so that under contention a copy of class 1 gets twice the bandwidth of a copy of class 0, while an idle
+// limiter lets any copy through immediately. The virtual time is the tag of the last chunk allowed through, as in
+// self-clocked fair queuing.
+type SharedLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	busy    bool
+	vtime   float64
+	pending sharedQueue
+}
+
+// NewSharedLimiter returns a SharedLimiter allowing bytesPerSecond in total. As with RateLimit, a non-positive rate
+// means no limit, and copies given the limiter are not throttled.
+func NewSharedLimiter(bytesPerSecond int64) *SharedLimiter {
+	return &SharedLimiter{rate: float64(bytesPerSecond)}
+}
+
+// sharedFlow is the state of a single copy within a SharedLimiter.
+type sharedFlow struct {
+	l      *SharedLimiter
+	weight float64
+	finish float64
+}
+
+// sharedRequest is a chunk of a copy waiting to be allowed through.
+type sharedRequest struct {
+	n        int
+	tag      float64
+	ready    chan struct{}
+	canceled bool
+}
+
+func (l *SharedLimiter) flow(class int) *sharedFlow {
+	if class < 0 {
+		class = 0
+	}
+	return &sharedFlow{l: l, weight: float64(class + 1)}
+}
+
+// wait blocks until n bytes of the flow are allowed through, or the context is canceled.
+func (f *sharedFlow) wait(ctx context.Context, n int) error {
+	l := f.l
+	if l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	start := f.finish
+	if l.vtime > start {
+		start = l.vtime
+	}
+	f.finish = start + float64(n)/f.weight
+
+	req := &sharedRequest{n: n, tag: f.finish, ready: make(chan struct{})}
+	heap.Push(&l.pending, req)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case <-req.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		req.canceled = true
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// dispatch allows the pending chunk with the lowest tag through if the limiter isn't busy with another one, and keeps
+// the limiter busy for the time the chunk takes at the shared rate. It must be called with the lock held.
+func (l *SharedLimiter) dispatch() {
+	for !l.busy && l.pending.Len() > 0 {
+		req := heap.Pop(&l.pending).(*sharedRequest)
+		if req.canceled {
+			continue
+		}
+
+		l.vtime = req.tag
+		l.busy = true
+		close(req.ready)
+
+		time.AfterFunc(time.Duration(float64(req.n)/l.rate*float64(time.Second)), func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.busy = false
+			l.dispatch()
+		})
+	}
+}
+
+// sharedQueue is a min-heap of requests ordered by tag.
+type sharedQueue []*sharedRequest
+
+func (q sharedQueue) Len() int           { return len(q) }
+func (q sharedQueue) Less(i, j int) bool { return q[i].tag < q[j].tag }
+func (q sharedQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *sharedQueue) Push(x any)        { *q = append(*q, x.(*sharedRequest)) }
+func (q *sharedQueue) Pop() any {
+	old := *q
+	req := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return req
+}
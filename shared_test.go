@@ -0,0 +1,112 @@
+package xio
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// zeroReader is an endless source of zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestSharedLimiter(t *testing.T) {
+	const (
+		rate     = 200 * 1024
+		duration = 300 * time.Millisecond
+	)
+
+	t.Run("combined rate", func(t *testing.T) {
+		l := NewSharedLimiter(rate)
+
+		ctx, cancel := context.WithTimeout(context.Background(), duration)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		counts := make([]int64, 3)
+		for i := range counts {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				counts[i], _ = Copy(ctx, io.Discard, zeroReader{}, BufferSize(1024), SharedLimit(l))
+			}(i)
+		}
+		wg.Wait()
+
+		var total int64
+		for _, n := range counts {
+			total += n
+		}
+		// the first chunk of the limiter goes through immediately
+		if max := int64(rate*duration.Seconds()) + 1024; total > max {
+			t.Fatalf("expected at most %d bytes to be copied in total but got %d", max, total)
+		}
+		if min := int64(rate * duration.Seconds() / 2); total < min {
+			t.Fatalf("expected at least %d bytes to be copied in total but got %d", min, total)
+		}
+	})
+
+	t.Run("priority classes", func(t *testing.T) {
+		l := NewSharedLimiter(rate)
+
+		ctx, cancel := context.WithTimeout(context.Background(), duration)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		var low, high int64
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			low, _ = Copy(ctx, io.Discard, zeroReader{}, BufferSize(1024), SharedLimit(l))
+		}()
+		go func() {
+			defer wg.Done()
+			high, _ = Copy(ctx, io.Discard, zeroReader{}, BufferSize(1024), SharedLimit(l), WithPriority(3))
+		}()
+		wg.Wait()
+
+		// class 3 weighs 4 times as much as class 0
+		if high < 3*low {
+			t.Fatalf("expected the high priority copy to get about 4 times the bandwidth but got %d bytes against %d", high, low)
+		}
+	})
+
+	t.Run("idle limiter", func(t *testing.T) {
+		l := NewSharedLimiter(1)
+
+		start := time.Now()
+		n, err := Copy(context.Background(), io.Discard, io.LimitReader(zeroReader{}, 1024), BufferSize(1024), SharedLimit(l))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 1024 {
+			t.Fatalf("expected n to be 1024 but got %d", n)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Fatalf("expected a single chunk to go through immediately but took %v", elapsed)
+		}
+	})
+	t.Run("non-positive rate means no limit", func(t *testing.T) {
+		l := NewSharedLimiter(0)
+
+		start := time.Now()
+		n, err := Copy(context.Background(), io.Discard, io.LimitReader(zeroReader{}, 10*1024), BufferSize(1024), SharedLimit(l))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 10*1024 {
+			t.Fatalf("expected n to be %d but got %d", 10*1024, n)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Fatalf("expected copy not to be throttled but took %v", elapsed)
+		}
+	})
+}
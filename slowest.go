@@ -0,0 +1,21 @@
+package xio
+
+import "time"
+
+// slowestOps tracks the longest read and write of a copy.
+type slowestOps struct {
+	read  time.Duration
+	write time.Duration
+}
+
+// since updates max with the time elapsed since start if it is longer.
+func (s *slowestOps) since(max *time.Duration, start time.Time) {
+	if d := time.Since(start); d > *max {
+		*max = d
+	}
+}
+
+func (s *slowestOps) report(fn func(kind string, d time.Duration)) {
+	fn("read", s.read)
+	fn("write", s.write)
+}
@@ -0,0 +1,96 @@
+package xio
+
+import (
+	"context"
+	"io"
+)
+
+// sparseMinHole is the length of the shortest run of zero bytes SparseCopy skips rather than writes. Shorter runs
+// are not worth a seek, and file systems allocate space by blocks anyway.
+const sparseMinHole = 4096
+
+// SparseCopy copies src into dst like Copy, but seeks forward over runs of at least 4096 zero bytes instead of writing
+// them, creating holes in dst when it is a file on a file system supporting sparse files. This saves disk space for
+// disk images and other mostly empty files. dst should be positioned at its end, typically a new or truncated file.
+// When src ends with a run of zeros, a single zero byte is written at the end so that dst has the expected length.
+// The returned n counts skipped zero bytes as written.
+func SparseCopy(ctx context.Context, dst io.WriteSeeker, src io.Reader, opts ...CopyOption) (int64, error) {
+	return Copy(ctx, dst, src, append(opts, func(c *copyoptions) {
+		c.sink = func(io.Writer) io.Writer {
+			return &sparseWriter{w: dst}
+		}
+	})...)
+}
+
+// sparseWriter defers runs of zero bytes until it knows how long they are, then seeks over them if they are long
+// enough and writes them otherwise.
+type sparseWriter struct {
+	w     io.WriteSeeker
+	zeros int64
+}
+
+var zeroBlock [sparseMinHole]byte
+
+func (sw *sparseWriter) Write(p []byte) (int, error) {
+	for i := 0; i < len(p); {
+		if p[i] == 0 {
+			start := i
+			for i < len(p) && p[i] == 0 {
+				i++
+			}
+			sw.zeros += int64(i - start)
+			continue
+		}
+
+		start := i
+		for i < len(p) && p[i] != 0 {
+			i++
+		}
+		// Zero bytes of p still pending when flushing them fails are not reported as written.
+		pending := start
+		if sw.zeros < int64(start) {
+			pending = int(sw.zeros)
+		}
+		if err := sw.flushZeros(); err != nil {
+			return start - pending, err
+		}
+		if n, err := sw.w.Write(p[start:i]); err != nil {
+			return start + n, err
+		}
+	}
+	return len(p), nil
+}
+
+// flushZeros seeks over the pending zero bytes or writes them.
+func (sw *sparseWriter) flushZeros() error {
+	if sw.zeros >= sparseMinHole {
+		if _, err := sw.w.Seek(sw.zeros, io.SeekCurrent); err != nil {
+			return err
+		}
+		sw.zeros = 0
+		return nil
+	}
+	for sw.zeros > 0 {
+		n, err := sw.w.Write(zeroBlock[:sw.zeros])
+		sw.zeros -= int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish writes out the trailing zero bytes, seeking over all but the last one which is written to set the length of
+// dst.
+func (sw *sparseWriter) finish() error {
+	if sw.zeros == 0 {
+		return nil
+	}
+	if sw.zeros >= sparseMinHole {
+		if _, err := sw.w.Seek(sw.zeros-1, io.SeekCurrent); err != nil {
+			return err
+		}
+		sw.zeros = 1
+	}
+	return sw.flushZeros()
+}
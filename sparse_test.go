@@ -0,0 +1,94 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// seekRecorder is an in memory io.WriteSeeker recording how many bytes are actually written to it.
+type seekRecorder struct {
+	data    []byte
+	offset  int64
+	written int
+}
+
+func (sr *seekRecorder) Write(p []byte) (int, error) {
+	if end := sr.offset + int64(len(p)); end > int64(len(sr.data)) {
+		sr.data = append(sr.data, make([]byte, end-int64(len(sr.data)))...)
+	}
+	copy(sr.data[sr.offset:], p)
+	sr.offset += int64(len(p))
+	sr.written += len(p)
+	return len(p), nil
+}
+
+func (sr *seekRecorder) Seek(offset int64, whence int) (int64, error) {
+	if whence != io.SeekCurrent {
+		panic("unexpected whence")
+	}
+	sr.offset += offset
+	return sr.offset, nil
+}
+
+func TestSparseCopy(t *testing.T) {
+	var payload []byte
+	payload = append(payload, "head"...)
+	payload = append(payload, make([]byte, 10000)...)
+	payload = append(payload, "mid"...)
+	payload = append(payload, make([]byte, 3)...)
+	payload = append(payload, "tail"...)
+	payload = append(payload, make([]byte, 8192)...)
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sparse.img")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		defer f.Close()
+
+		n, err := SparseCopy(context.Background(), f, bytes.NewReader(payload), BufferSize(1000))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != int64(len(payload)) {
+			t.Fatalf("expected n to be %d but got %d", len(payload), n)
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if info.Size() != int64(len(payload)) {
+			t.Fatalf("expected logical size to be %d but got %d", len(payload), info.Size())
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if !bytes.Equal(content, payload) {
+			t.Fatal("expected file content to match the payload")
+		}
+	})
+
+	t.Run("long zero runs are skipped", func(t *testing.T) {
+		dst := &seekRecorder{}
+
+		if _, err := SparseCopy(context.Background(), dst, bytes.NewReader(payload), BufferSize(1000)); err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if !bytes.Equal(dst.data, payload) {
+			t.Fatal("expected content to match the payload")
+		}
+
+		// head, mid, the short run of zeros, tail, and the last zero byte.
+		if expected := 4 + 3 + 3 + 4 + 1; dst.written != expected {
+			t.Fatalf("expected %d bytes to be written but got %d", expected, dst.written)
+		}
+	})
+}
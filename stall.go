@@ -0,0 +1,66 @@
+package xio
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrPeerStalled is returned when writes to a destination repeatedly time out without making any progress.
+var ErrPeerStalled = errors.New("peer stalled")
+
+// writeDeadliner is implemented by net.Conn and *os.File.
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+type stallDetection struct {
+	timeout   time.Duration
+	maxStalls int
+}
+
+// stallWriter writes to w, which ultimately writes to conn, retrying writes that time out until too many
+// consecutive attempts make no progress.
+type stallWriter struct {
+	w     io.Writer
+	conn  writeDeadliner
+	stall stallDetection
+}
+
+func (sw *stallWriter) Write(p []byte) (written int, err error) {
+	defer func() {
+		if resetErr := sw.conn.SetWriteDeadline(time.Time{}); err == nil {
+			err = resetErr
+		}
+	}()
+
+	var stalls int
+	for written < len(p) {
+		if err := sw.conn.SetWriteDeadline(time.Now().Add(sw.stall.timeout)); err != nil {
+			return written, err
+		}
+
+		n, err := sw.w.Write(p[written:])
+		written += n
+		if err == nil {
+			continue
+		}
+		if !isTimeout(err) {
+			return written, err
+		}
+
+		if n > 0 {
+			stalls = 0
+			continue
+		}
+		if stalls++; stalls >= sw.stall.maxStalls {
+			return written, ErrPeerStalled
+		}
+	}
+	return written, nil
+}
+
+func isTimeout(err error) bool {
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
@@ -0,0 +1,84 @@
+package xio
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stallingConn accepts at most accept bytes per write before timing out, and times out without writing anything
+// once its budget of bytes is spent.
+type stallingConn struct {
+	budget    int
+	accept    int
+	attempts  int
+	deadlines int
+	received  strings.Builder
+}
+
+func (c *stallingConn) SetWriteDeadline(t time.Time) error {
+	if !t.IsZero() {
+		c.deadlines++
+	}
+	return nil
+}
+
+func (c *stallingConn) Write(p []byte) (int, error) {
+	c.attempts++
+
+	n := len(p)
+	if n > c.accept {
+		n = c.accept
+	}
+	if n > c.budget {
+		n = c.budget
+	}
+	c.budget -= n
+	c.received.Write(p[:n])
+
+	if n < len(p) {
+		return n, os.ErrDeadlineExceeded
+	}
+	return n, nil
+}
+
+func TestDetectStall(t *testing.T) {
+	t.Run("stalled peer", func(t *testing.T) {
+		conn := &stallingConn{budget: 4, accept: 100}
+
+		n, err := Copy(context.Background(), conn, strings.NewReader("0123456789"), DetectStall(time.Second, 3))
+		if err != ErrPeerStalled {
+			t.Fatalf("expected err to be %#q but got %#q", ErrPeerStalled, err)
+		}
+		if n != 4 {
+			t.Fatalf("expected n to be 4 but got %d", n)
+		}
+		// one partial write followed by 3 attempts without progress
+		if conn.attempts != 4 || conn.deadlines != 4 {
+			t.Fatalf("expected 4 attempts with deadlines but got %d attempts and %d deadlines", conn.attempts, conn.deadlines)
+		}
+	})
+
+	t.Run("slow but progressing peer", func(t *testing.T) {
+		conn := &stallingConn{budget: 100, accept: 1}
+
+		n, err := Copy(context.Background(), conn, strings.NewReader("0123456789"), DetectStall(time.Second, 3))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 10 || conn.received.String() != "0123456789" {
+			t.Fatalf("expected all data to be written but got %d bytes: %q", n, conn.received.String())
+		}
+	})
+
+	t.Run("without the option timeouts are returned", func(t *testing.T) {
+		conn := &stallingConn{budget: 100, accept: 1}
+
+		_, err := Copy(context.Background(), conn, strings.NewReader("0123456789"))
+		if err != os.ErrDeadlineExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", os.ErrDeadlineExceeded, err)
+		}
+	})
+}
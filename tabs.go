@@ -0,0 +1,67 @@
+package xio
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// ExpandTabsWriter returns a writer that replaces every tab written to it with the spaces reaching the next multiple
+// of tabWidth columns before writing to w, like the expand command. Columns are counted in UTF-8 characters, carry
+// over from one Write to the next and restart after a newline or a carriage return. A tabWidth below 1 defaults to 8.
+// Write reports the number of bytes of p consumed, not the number of bytes written to w.
+func ExpandTabsWriter(w io.Writer, tabWidth int) io.Writer {
+	if tabWidth < 1 {
+		tabWidth = 8
+	}
+	return &expandTabsWriter{w: w, spaces: bytes.Repeat([]byte{' '}, tabWidth)}
+}
+
+type expandTabsWriter struct {
+	w      io.Writer
+	spaces []byte
+	col    int
+}
+
+func (ew *expandTabsWriter) Write(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		segment := p[n:]
+		i := bytes.IndexByte(segment, '\t')
+		if i >= 0 {
+			segment = segment[:i]
+		}
+
+		if len(segment) > 0 {
+			wn, err := ew.w.Write(segment)
+			ew.advance(segment[:wn])
+			n += wn
+			if err != nil {
+				return n, err
+			}
+		}
+		if i < 0 {
+			break
+		}
+
+		spaces := ew.spaces[ew.col%len(ew.spaces):]
+		if _, err := ew.w.Write(spaces); err != nil {
+			return n, err
+		}
+		ew.col += len(spaces)
+		n++
+	}
+	return n, nil
+}
+
+// advance moves the column past b.
+func (ew *expandTabsWriter) advance(b []byte) {
+	for _, c := range b {
+		switch {
+		case c == '\n' || c == '\r':
+			ew.col = 0
+		case utf8.RuneStart(c):
+			ew.col++
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestExpandTabsWriter(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		tabWidth int
+		src      string
+		expected string
+	}{
+		{name: "leading tabs", tabWidth: 4, src: "\tone\n\t\ttwo\n", expected: "    one\n        two\n"},
+		{name: "tabs after text", tabWidth: 4, src: "a\tb\nabc\td\nabcd\te\n", expected: "a   b\nabc d\nabcd    e\n"},
+		{name: "multibyte characters", tabWidth: 4, src: "é\tx\n日本\ty\n", expected: "é   x\n日本  y\n"},
+		{name: "carriage return", tabWidth: 8, src: "ab\r\tc", expected: "ab\r        c"},
+		{name: "default width", tabWidth: 0, src: "ab\tc", expected: "ab      c"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, src := range []struct {
+				kind string
+				opts []CopyOption
+			}{
+				{kind: "whole"},
+				// tabs and multibyte characters are split across writes at every position
+				{kind: "one byte at a time", opts: []CopyOption{BufferSize(1)}},
+				{kind: "three bytes at a time", opts: []CopyOption{BufferSize(3)}},
+			} {
+				var dst bytes.Buffer
+				n, err := Copy(context.Background(), ExpandTabsWriter(&dst, tc.tabWidth), iotest.HalfReader(strings.NewReader(tc.src)), src.opts...)
+				if err != nil {
+					t.Fatalf("%s: expected err to be nil but got %#q", src.kind, err)
+				}
+				if n != int64(len(tc.src)) {
+					t.Fatalf("%s: expected n to be %d but got %d", src.kind, len(tc.src), n)
+				}
+				if dst.String() != tc.expected {
+					t.Fatalf("%s: expected %q but got %q", src.kind, tc.expected, dst.String())
+				}
+			}
+		})
+	}
+}
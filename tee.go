@@ -0,0 +1,33 @@
+package xio
+
+import (
+	"context"
+	"io"
+)
+
+// CopyTeeReader starts copying src into dst like Copy and returns a reader yielding the same bytes, so that a
+// downstream consumer sees the stream while dst receives it. Every chunk is written to dst and then handed to the
+// returned reader through a pipe, so the copy only progresses as fast as the returned reader is read: it must be
+// drained, or the context canceled, for the copy to complete. Once the copy ends the reader returns io.EOF, or the
+// error that ended it, including the context error on cancelation.
+func CopyTeeReader(ctx context.Context, dst io.Writer, src io.Reader, opts ...CopyOption) io.Reader {
+	pr, pw := io.Pipe()
+
+	go func() {
+		// Closing the pipe on cancelation unblocks a write to it if the reader isn't being drained.
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-ctx.Done():
+				pw.CloseWithError(ctx.Err())
+			case <-stop:
+			}
+		}()
+
+		_, err := Copy(ctx, io.MultiWriter(dst, pw), src, opts...)
+		pw.CloseWithError(err)
+	}()
+
+	return pr
+}
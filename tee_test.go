@@ -0,0 +1,60 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyTeeReader(t *testing.T) {
+	t.Run("reader yields the copied bytes", func(t *testing.T) {
+		data := strings.Repeat("tee through ", 1000)
+
+		var dst bytes.Buffer
+		r := CopyTeeReader(context.Background(), &dst, strings.NewReader(data), BufferSize(100))
+
+		seen, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if string(seen) != data {
+			t.Fatalf("expected reader to yield the copied data but got %d bytes", len(seen))
+		}
+		if dst.String() != data {
+			t.Fatalf("expected dst to receive identical bytes but got %d bytes", dst.Len())
+		}
+	})
+
+	t.Run("copy errors are returned by the reader", func(t *testing.T) {
+		readErr := errors.New("reader broke!")
+
+		r := CopyTeeReader(context.Background(), &bytes.Buffer{}, ReaderFunc(func(b []byte) (int, error) {
+			return copy(b, "partial"), readErr
+		}))
+
+		seen, err := io.ReadAll(r)
+		if err != readErr {
+			t.Fatalf("expected err to be %#q but got %#q", readErr, err)
+		}
+		if string(seen) != "partial" {
+			t.Fatalf("expected %q but got %q", "partial", seen)
+		}
+	})
+
+	t.Run("cancelation unblocks an undrained copy", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		r := CopyTeeReader(ctx, &bytes.Buffer{}, strings.NewReader("never read"))
+
+		time.Sleep(40 * time.Millisecond)
+
+		if _, err := io.ReadAll(r); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be context deadline exceeded but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"text/template"
+)
+
+// CopyTemplate reads src as a text/template, executes it with data and writes the result to dst. Since templates
+// can't be executed as a stream, src is read fully first, bounded by the MaxBytes option when it is given. Reading the
+// template and writing the result are both cancelable. The options apply to writing the result, apart from MaxBytes
+// which only bounds the template. The returned count is the number of bytes of the result written to dst.
+func CopyTemplate(ctx context.Context, dst io.Writer, src io.Reader, data any, opts ...CopyOption) (int64, error) {
+	var options copyoptions
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	var source bytes.Buffer
+	if _, err := Copy(ctx, &source, src, MaxBytes(options.maxBytes)); err != nil {
+		return 0, err
+	}
+
+	tmpl, err := template.New("xio").Parse(source.String())
+	if err != nil {
+		return 0, err
+	}
+
+	var result bytes.Buffer
+	if err := tmpl.Execute(&result, data); err != nil {
+		return 0, err
+	}
+
+	return Copy(ctx, dst, &result, append(opts, MaxBytes(0))...)
+}
@@ -0,0 +1,70 @@
+package xio
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCopyTemplate(t *testing.T) {
+	t.Run("renders template", func(t *testing.T) {
+		var dst strings.Builder
+
+		data := struct {
+			Name  string
+			Items []string
+		}{
+			Name:  "xio",
+			Items: []string{"copy", "read", "write"},
+		}
+
+		n, err := CopyTemplate(
+			context.Background(),
+			&dst,
+			strings.NewReader("hello {{ .Name }}:{{ range .Items }} {{ . }}{{ end }}"),
+			data,
+			BufferSize(4),
+		)
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+
+		expected := "hello xio: copy read write"
+		if dst.String() != expected {
+			t.Fatalf("expected %q but got %q", expected, dst.String())
+		}
+		if n != int64(len(expected)) {
+			t.Fatalf("expected n to be %d but got %d", len(expected), n)
+		}
+	})
+
+	t.Run("template larger than max bytes", func(t *testing.T) {
+		var dst strings.Builder
+
+		_, err := CopyTemplate(context.Background(), &dst, strings.NewReader("{{ . }}{{ . }}{{ . }}"), "x", MaxBytes(10))
+		if err != ErrMaxBytesExceeded {
+			t.Fatalf("expected err to be %#q but got %#q", ErrMaxBytesExceeded, err)
+		}
+		if dst.Len() != 0 {
+			t.Fatalf("expected nothing to be written but got %q", dst.String())
+		}
+	})
+
+	t.Run("result is not bounded by max bytes", func(t *testing.T) {
+		var dst strings.Builder
+
+		_, err := CopyTemplate(context.Background(), &dst, strings.NewReader("{{ . }}{{ . }}"), "0123456789", MaxBytes(16))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if dst.String() != "01234567890123456789" {
+			t.Fatalf("expected the full result but got %q", dst.String())
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		if _, err := CopyTemplate(context.Background(), &strings.Builder{}, strings.NewReader("{{ .Name "), nil); err == nil {
+			t.Fatal("expected a parse error")
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package xio
+
+import (
+	"context"
+	"io"
+)
+
+// textSniffLen is the number of bytes at the start of src used to tell text from binary.
+const textSniffLen = 512
+
+// CopyTextAware copies src into dst like Copy, and calls onDetect before writing anything with a guess of whether src
+// holds text or binary data, so that callers can decide on line ending conversion for example. The guess is based on
+// the first 512 bytes of src: data holding a null byte, or more than 10% of control characters other than common
+// whitespace, is considered binary. Empty data is considered text. The sniffed bytes are copied like the rest.
+func CopyTextAware(ctx context.Context, dst io.Writer, src io.Reader, onDetect func(isText bool), opts ...CopyOption) (int64, error) {
+	return Copy(ctx, dst, &textSniffReader{r: src, onDetect: onDetect}, opts...)
+}
+
+// textSniffReader sniffs the start of r on its first Read and reports whether it looks like text.
+type textSniffReader struct {
+	r        io.Reader
+	onDetect func(isText bool)
+	sniffed  []byte
+	err      error
+	done     bool
+}
+
+func (tr *textSniffReader) Read(p []byte) (int, error) {
+	if !tr.done {
+		tr.done = true
+
+		buf := make([]byte, textSniffLen)
+		n, err := io.ReadFull(tr.r, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		tr.sniffed, tr.err = buf[:n], err
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		tr.onDetect(isText(tr.sniffed))
+	}
+
+	if len(tr.sniffed) > 0 {
+		n := copy(p, tr.sniffed)
+		tr.sniffed = tr.sniffed[n:]
+		return n, nil
+	}
+	if tr.err != nil {
+		return 0, tr.err
+	}
+	return tr.r.Read(p)
+}
+
+// isText guesses whether data is text: it holds no null byte and at most 10% of control characters other than common
+// whitespace. Bytes above 0x7f are considered text to allow for UTF-8 and other encodings.
+func isText(data []byte) bool {
+	var control int
+	for _, b := range data {
+		switch {
+		case b == 0:
+			return false
+		case b == '\t', b == '\n', b == '\r', b == '\f', b == '\b', b == 0x1b:
+		case b < 0x20, b == 0x7f:
+			control++
+		}
+	}
+	return control*10 <= len(data)
+}
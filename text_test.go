@@ -0,0 +1,55 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCopyTextAware(t *testing.T) {
+	text := strings.Repeat("plain text, with üñíçødé\r\n\tand whitespace\n", 30)
+
+	binary := make([]byte, 1000)
+	for i := range binary {
+		binary[i] = byte(i * 7)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		data     []byte
+		expected bool
+	}{
+		{name: "text", data: []byte(text), expected: true},
+		{name: "binary", data: binary, expected: false},
+		{name: "control characters", data: []byte(strings.Repeat("ab\x01\x02", 10)), expected: false},
+		{name: "empty", data: nil, expected: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var detections []bool
+			var dst bytes.Buffer
+
+			n, err := CopyTextAware(
+				context.Background(),
+				WriterFunc(func(b []byte) (int, error) {
+					if len(detections) == 0 {
+						t.Error("expected detection to happen before anything is written")
+					}
+					return dst.Write(b)
+				}),
+				bytes.NewReader(tc.data),
+				func(isText bool) { detections = append(detections, isText) },
+				BufferSize(100),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil but got %#q", err)
+			}
+			if n != int64(len(tc.data)) || !bytes.Equal(dst.Bytes(), tc.data) {
+				t.Fatalf("expected data to be preserved but got %d bytes", n)
+			}
+			if len(detections) != 1 || detections[0] != tc.expected {
+				t.Fatalf("expected a single detection of %v but got %v", tc.expected, detections)
+			}
+		})
+	}
+}
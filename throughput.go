@@ -0,0 +1,88 @@
+package xio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrTooSlow is returned by a MinThroughputReader when data arrives slower than its minimum throughput.
+var ErrTooSlow = errors.New("throughput below minimum")
+
+// MinThroughputReader returns a reader that fails with ErrTooSlow once the average throughput of r since the first
+// Read drops below minBytesPerSec, which protects servers reading request bodies against slowloris attacks. The
+// throughput is only enforced once grace has elapsed, leaving time for the transfer to start. Since a slow client may
+// send nothing at all, reads from r happen on a separate goroutine and are abandoned as soon as the throughput would
+// drop below the minimum, or the context is canceled, in which case the context error is returned. Errors are final.
+// A minBytesPerSec of zero or less disables the check.
+func MinThroughputReader(ctx context.Context, r io.Reader, minBytesPerSec int64, grace time.Duration) io.Reader {
+	if minBytesPerSec <= 0 {
+		return r
+	}
+	return &minThroughputReader{
+		ctx:     ctx,
+		r:       r,
+		min:     float64(minBytesPerSec),
+		grace:   grace,
+		results: make(chan minThroughputResult, 1),
+	}
+}
+
+type minThroughputReader struct {
+	ctx     context.Context
+	r       io.Reader
+	min     float64
+	grace   time.Duration
+	start   time.Time
+	read    int64
+	buf     []byte
+	results chan minThroughputResult
+	err     error
+}
+
+type minThroughputResult struct {
+	n   int
+	err error
+}
+
+func (mr *minThroughputReader) Read(p []byte) (int, error) {
+	if mr.err != nil {
+		return 0, mr.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if mr.start.IsZero() {
+		mr.start = time.Now()
+	}
+
+	// The read is made into a buffer of its own, which an abandoned read keeps writing to.
+	if len(mr.buf) < len(p) {
+		mr.buf = make([]byte, len(p))
+	}
+	go func(b []byte) {
+		n, err := mr.r.Read(b)
+		mr.results <- minThroughputResult{n: n, err: err}
+	}(mr.buf[:len(p)])
+
+	// The throughput drops below the minimum once the time elapsed exceeds the time the bytes read should have taken.
+	allowed := time.Duration(float64(mr.read) / mr.min * float64(time.Second))
+	if allowed < mr.grace {
+		allowed = mr.grace
+	}
+	timer := time.NewTimer(time.Until(mr.start.Add(allowed)))
+	defer timer.Stop()
+
+	select {
+	case res := <-mr.results:
+		n := copy(p, mr.buf[:res.n])
+		mr.read += int64(n)
+		return n, res.err
+	case <-timer.C:
+		mr.err = ErrTooSlow
+	case <-mr.ctx.Done():
+		mr.err = mr.ctx.Err()
+	}
+	return 0, mr.err
+}
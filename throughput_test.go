@@ -0,0 +1,81 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMinThroughputReader(t *testing.T) {
+	t.Run("fast enough", func(t *testing.T) {
+		src := strings.Repeat("x", 1000)
+
+		var dst bytes.Buffer
+		n, err := Copy(context.Background(), &dst, MinThroughputReader(context.Background(), strings.NewReader(src), 1000, 10*time.Millisecond), BufferSize(10))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 1000 || dst.String() != src {
+			t.Fatalf("expected all data to be copied but got %d bytes", n)
+		}
+	})
+
+	t.Run("below the threshold", func(t *testing.T) {
+		// 10 bytes every 10ms is 1000 bytes per second, below the 5000 required
+		slow := ReaderFunc(func(b []byte) (int, error) {
+			time.Sleep(10 * time.Millisecond)
+			return copy(b, "0123456789"), nil
+		})
+
+		start := time.Now()
+		n, err := Copy(context.Background(), io.Discard, MinThroughputReader(context.Background(), slow, 5000, 50*time.Millisecond))
+		if !errors.Is(err, ErrTooSlow) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrTooSlow, err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Fatalf("expected the grace period to be respected but failed after %v", elapsed)
+		}
+		if n == 0 {
+			t.Fatalf("expected some data to be copied during the grace period")
+		}
+	})
+
+	t.Run("stalled", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		stalled := ReaderFunc(func(b []byte) (int, error) {
+			<-block
+			return 0, io.EOF
+		})
+
+		start := time.Now()
+		_, err := Copy(context.Background(), io.Discard, MinThroughputReader(context.Background(), stalled, 1, 20*time.Millisecond))
+		if !errors.Is(err, ErrTooSlow) {
+			t.Fatalf("expected err to be %#q but got %#q", ErrTooSlow, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("expected a stalled read to be abandoned but took %v", elapsed)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		r := MinThroughputReader(ctx, ReaderFunc(func(b []byte) (int, error) {
+			<-block
+			return 0, io.EOF
+		}), 1, time.Hour)
+		if _, err := r.Read(make([]byte, 4)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err to be %#q but got %#q", context.DeadlineExceeded, err)
+		}
+	})
+}
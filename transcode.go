@@ -0,0 +1,14 @@
+package xio
+
+import (
+	"io"
+
+	"golang.org/x/text/encoding"
+)
+
+// TranscodeReader returns a reader that decodes the text read from r to UTF-8 with decoder, such as
+// charmap.ISO8859_1.NewDecoder(), for charsets beyond the ones supported by CharsetReader. Multibyte sequences split
+// across reads from r are held back until complete. A decoder is stateful: it must not be shared by several readers.
+func TranscodeReader(r io.Reader, decoder *encoding.Decoder) io.Reader {
+	return decoder.Reader(r)
+}
@@ -0,0 +1,54 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestTranscodeReader(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		decoder  func() *encoding.Decoder
+		src      []byte
+		expected string
+	}{
+		{
+			name:     "latin1",
+			decoder:  charmap.ISO8859_1.NewDecoder,
+			src:      []byte("caf\xe9 cr\xe8me br\xfbl\xe9e \xa9 \xbd"),
+			expected: "café crème brûlée © ½",
+		},
+		{
+			name:     "shift jis",
+			decoder:  japanese.ShiftJIS.NewDecoder,
+			src:      []byte("\x93\xfa\x96\x7b\x8c\xea text"),
+			expected: "日本語 text",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, src := range []struct {
+				kind string
+				r    io.Reader
+			}{
+				{kind: "whole", r: bytes.NewReader(tc.src)},
+				// multibyte sequences are split across reads
+				{kind: "one byte at a time", r: iotest.OneByteReader(bytes.NewReader(tc.src))},
+			} {
+				var dst bytes.Buffer
+				if _, err := Copy(context.Background(), &dst, TranscodeReader(src.r, tc.decoder()), BufferSize(3)); err != nil {
+					t.Fatalf("%s: expected err to be nil but got %#q", src.kind, err)
+				}
+				if dst.String() != tc.expected {
+					t.Fatalf("%s: expected %q but got %q", src.kind, tc.expected, dst.String())
+				}
+			}
+		})
+	}
+}
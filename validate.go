@@ -0,0 +1,30 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// CopyValidate reads the first prefixLen bytes of src and passes them to validate, for example to check a magic
+// number, before copying anything. If validate returns an error the copy is aborted with it and nothing is written to
+// dst. Otherwise the whole content of src, prefix included, is copied into dst like Copy. If src holds fewer than
+// prefixLen bytes, validate is given all of them. A negative prefixLen is an error, returned before anything is read.
+func CopyValidate(ctx context.Context, dst io.Writer, src io.Reader, validate func(prefix []byte) error, prefixLen int, opts ...CopyOption) (int64, error) {
+	if prefixLen < 0 {
+		return 0, fmt.Errorf("xio: CopyValidate: invalid prefix length %d", prefixLen)
+	}
+	prefix := make([]byte, prefixLen)
+
+	n, err := ReadFullN(ctx, src, [][]byte{prefix})
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+	prefix = prefix[:n]
+
+	if err := validate(prefix); err != nil {
+		return 0, err
+	}
+	return Copy(ctx, dst, io.MultiReader(bytes.NewReader(prefix), src), opts...)
+}
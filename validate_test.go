@@ -0,0 +1,79 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCopyValidate(t *testing.T) {
+	errNotPNG := errors.New("not a PNG")
+
+	validatePNG := func(prefix []byte) error {
+		if !bytes.Equal(prefix, []byte("\x89PNG")) {
+			return errNotPNG
+		}
+		return nil
+	}
+
+	t.Run("valid prefix", func(t *testing.T) {
+		var dst bytes.Buffer
+		n, err := CopyValidate(context.Background(), &dst, strings.NewReader("\x89PNG image data"), validatePNG, 4, BufferSize(3))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 15 || dst.String() != "\x89PNG image data" {
+			t.Fatalf("expected the whole content to be copied but got %d bytes: %q", n, dst.String())
+		}
+	})
+
+	for _, tc := range []struct {
+		name string
+		src  string
+	}{
+		{name: "invalid prefix", src: "GIF89a image data"},
+		{name: "shorter than the prefix", src: "\x89P"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := CopyValidate(
+				context.Background(),
+				WriterFunc(func(b []byte) (int, error) {
+					t.Errorf("expected nothing to be written but got %q", b)
+					return len(b), nil
+				}),
+				strings.NewReader(tc.src),
+				validatePNG,
+				4,
+			)
+			if err != errNotPNG {
+				t.Fatalf("expected err to be %#q but got %#q", errNotPNG, err)
+			}
+			if n != 0 {
+				t.Fatalf("expected n to be 0 but got %d", n)
+			}
+		})
+	}
+	t.Run("negative prefix length", func(t *testing.T) {
+		n, err := CopyValidate(
+			context.Background(),
+			WriterFunc(func(b []byte) (int, error) {
+				t.Errorf("expected nothing to be written but got %q", b)
+				return len(b), nil
+			}),
+			strings.NewReader("\x89PNG image data"),
+			func(prefix []byte) error {
+				t.Error("expected validate not to be called")
+				return nil
+			},
+			-1,
+		)
+		if err == nil {
+			t.Fatal("expected an error for a negative prefix length")
+		}
+		if n != 0 {
+			t.Fatalf("expected n to be 0 but got %d", n)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package xio
+
+import (
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"io"
+)
+
+// ErrChecksumMismatch is returned by a verified reader when the trailer of its input doesn't match the hash of the body.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// NewVerifiedReader returns a reader over the body of r, which is expected to end with a hashLen bytes trailer holding
+// the checksum of the body computed with h. The body is streamed while the last hashLen bytes read are withheld, and
+// once r reaches EOF the withheld trailer is compared to the hash of the body: the reader returns io.EOF if they match
+// and ErrChecksumMismatch otherwise. The trailer itself is never returned. If r holds fewer than hashLen bytes, the
+// reader fails with io.ErrUnexpectedEOF. Since the body is only verified at the end, consumers must not act on the
+// data before reaching io.EOF.
+func NewVerifiedReader(r io.Reader, hashLen int, h func() hash.Hash) io.Reader {
+	return &verifiedReader{r: r, hashLen: hashLen, h: h()}
+}
+
+type verifiedReader struct {
+	r       io.Reader
+	hashLen int
+	h       hash.Hash
+	// pending holds the bytes read from r but not returned yet, of which the last hashLen are withheld.
+	pending []byte
+	scratch []byte
+	eof     bool
+	err     error
+}
+
+func (vr *verifiedReader) Read(p []byte) (int, error) {
+	if vr.err != nil {
+		return 0, vr.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		if releasable := len(vr.pending) - vr.hashLen; releasable > 0 {
+			n := copy(p, vr.pending[:releasable])
+			vr.h.Write(p[:n])
+			vr.pending = vr.pending[n:]
+			return n, nil
+		}
+
+		if vr.eof {
+			vr.err = vr.verify()
+			return 0, vr.err
+		}
+
+		if len(vr.scratch) < len(p) {
+			vr.scratch = make([]byte, len(p))
+		}
+		n, err := vr.r.Read(vr.scratch)
+		vr.pending = append(vr.pending, vr.scratch[:n]...)
+		if err == io.EOF {
+			vr.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (vr *verifiedReader) verify() error {
+	if len(vr.pending) < vr.hashLen {
+		return io.ErrUnexpectedEOF
+	}
+	if subtle.ConstantTimeCompare(vr.h.Sum(nil), vr.pending) != 1 {
+		return ErrChecksumMismatch
+	}
+	return io.EOF
+}
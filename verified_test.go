@@ -0,0 +1,75 @@
+package xio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestVerifiedReader(t *testing.T) {
+	body := strings.Repeat("verified body ", 20)
+	sum := sha256.Sum256([]byte(body))
+
+	t.Run("valid trailer", func(t *testing.T) {
+		for _, src := range []struct {
+			kind string
+			r    io.Reader
+		}{
+			{kind: "whole", r: strings.NewReader(body + string(sum[:]))},
+			// the trailer is split across reads
+			{kind: "one byte at a time", r: iotest.OneByteReader(strings.NewReader(body + string(sum[:])))},
+		} {
+			var dst bytes.Buffer
+			n, err := Copy(context.Background(), &dst, NewVerifiedReader(src.r, sha256.Size, sha256.New), BufferSize(16))
+			if err != nil {
+				t.Fatalf("%s: expected err to be nil but got %#q", src.kind, err)
+			}
+			if n != int64(len(body)) || dst.String() != body {
+				t.Fatalf("%s: expected the body without trailer but got %d bytes", src.kind, n)
+			}
+		}
+	})
+
+	t.Run("corrupted trailer", func(t *testing.T) {
+		corrupted := sum
+		corrupted[0] ^= 0xff
+
+		_, err := Copy(context.Background(), io.Discard, NewVerifiedReader(strings.NewReader(body+string(corrupted[:])), sha256.Size, sha256.New))
+		if err != ErrChecksumMismatch {
+			t.Fatalf("expected err to be %#q but got %#q", ErrChecksumMismatch, err)
+		}
+	})
+
+	t.Run("corrupted body", func(t *testing.T) {
+		_, err := Copy(context.Background(), io.Discard, NewVerifiedReader(strings.NewReader("tampered"+body[8:]+string(sum[:])), sha256.Size, sha256.New))
+		if err != ErrChecksumMismatch {
+			t.Fatalf("expected err to be %#q but got %#q", ErrChecksumMismatch, err)
+		}
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		empty := sha256.Sum256(nil)
+
+		n, err := Copy(context.Background(), io.Discard, NewVerifiedReader(bytes.NewReader(empty[:]), sha256.Size, sha256.New))
+		if err != nil {
+			t.Fatalf("expected err to be nil but got %#q", err)
+		}
+		if n != 0 {
+			t.Fatalf("expected no body but got %d bytes", n)
+		}
+	})
+
+	t.Run("shorter than the trailer", func(t *testing.T) {
+		n, err := Copy(context.Background(), io.Discard, NewVerifiedReader(bytes.NewReader(sum[:10]), sha256.Size, sha256.New))
+		if err != io.ErrUnexpectedEOF {
+			t.Fatalf("expected err to be %#q but got %#q", io.ErrUnexpectedEOF, err)
+		}
+		if n != 0 {
+			t.Fatalf("expected no body but got %d bytes", n)
+		}
+	})
+}